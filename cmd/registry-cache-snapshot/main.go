@@ -0,0 +1,75 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// registry-cache-snapshot exports and imports the contents of a registry cache
+// volume. It is intended to run as a Job next to the cache's PersistentVolumeClaim,
+// e.g. to seed a new cluster's cache from an already warmed up one.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/snapshot"
+)
+
+func main() {
+	if err := newSnapshotCommand().Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func newSnapshotCommand() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "registry-cache-snapshot",
+		Short: "Export and import the contents of a registry cache volume",
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create <cache-directory> <snapshot-file>",
+		Short: "Archive the contents of a cache directory into a snapshot file",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			file, err := os.Create(args[1])
+			if err != nil {
+				return err
+			}
+			if err := snapshot.Create(args[0], file); err != nil {
+				file.Close()
+				return err
+			}
+			return file.Close()
+		},
+	}
+
+	restoreCmd := &cobra.Command{
+		Use:   "restore <snapshot-file> <cache-directory>",
+		Short: "Extract a snapshot file into a cache directory",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			file, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			return snapshot.Restore(file, args[1])
+		},
+	}
+
+	rootCmd.AddCommand(createCmd, restoreCmd)
+	return rootCmd
+}
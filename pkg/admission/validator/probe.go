@@ -0,0 +1,140 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gardener/gardener/pkg/apis/core"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	api "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/controller"
+)
+
+// upstreamProbeTimeout bounds the reachability probe of a single upstream so that unreachable
+// upstreams cannot slow down admission noticeably.
+const upstreamProbeTimeout = 2 * time.Second
+
+// probeWarningHandler wraps the admission handler of the webhook so that failed upstream
+// reachability probes are surfaced to the user as admission warnings. The extensions webhook
+// library only lets a validator deny a request, so the probes run outside of the shoot
+// validator, on otherwise allowed responses.
+type probeWarningHandler struct {
+	delegate   admission.Handler
+	decoder    runtime.Decoder
+	httpClient *http.Client
+}
+
+// InjectScheme injects the given scheme into the handler.
+func (h *probeWarningHandler) InjectScheme(scheme *runtime.Scheme) error {
+	h.decoder = serializer.NewCodecFactory(scheme).UniversalDecoder()
+	return nil
+}
+
+// InjectFunc passes the injected dependencies on to the wrapped handler.
+func (h *probeWarningHandler) InjectFunc(f inject.Func) error {
+	return f(h.delegate)
+}
+
+// Handle calls the wrapped handler and attaches the probe warnings to an allowed response.
+func (h *probeWarningHandler) Handle(ctx context.Context, request admission.Request) admission.Response {
+	response := h.delegate.Handle(ctx, request)
+	if !response.Allowed || len(request.Object.Raw) == 0 {
+		return response
+	}
+
+	shoot := &core.Shoot{}
+	if _, _, err := h.decoder.Decode(request.Object.Raw, nil, shoot); err != nil {
+		return response
+	}
+
+	return response.WithWarnings(h.probeWarnings(ctx, shoot)...)
+}
+
+// probeWarnings returns a message per unreachable upstream of a shoot that opted into the
+// reachability probes. The probes are advisory only: they catch typos early, but an
+// unreachable upstream never blocks the request (it may simply not be reachable from the
+// admission component), so decode problems yield no warnings instead of an error.
+func (h *probeWarningHandler) probeWarnings(ctx context.Context, shoot *core.Shoot) []string {
+	var providerConfig *runtime.RawExtension
+	for _, ex := range shoot.Spec.Extensions {
+		if ex.Type == controller.Type {
+			providerConfig = ex.ProviderConfig
+			break
+		}
+	}
+	if providerConfig == nil {
+		return nil
+	}
+
+	registryConfig, err := decodeRegistryConfig(h.decoder, providerConfig, field.NewPath("providerConfig"))
+	if err != nil || !pointer.BoolDeref(registryConfig.ProbeUpstreams, false) {
+		return nil
+	}
+
+	warnings := probeUpstreams(ctx, h.httpClient, registryConfig)
+	for _, warning := range warnings {
+		logger.Info("Upstream reachability probe failed", "shoot", shoot.Name, "warning", warning)
+	}
+
+	return warnings
+}
+
+// probeUpstreams checks best-effort whether the configured upstreams respond on their registry
+// API and returns a message per unreachable upstream.
+func probeUpstreams(ctx context.Context, httpClient *http.Client, registryConfig *api.RegistryConfig) []string {
+	var warnings []string
+	for _, cache := range registryConfig.Caches {
+		if err := probeUpstream(ctx, httpClient, controller.UpstreamRegistryURL(cache.Upstream, pointer.BoolDeref(cache.Insecure, false))); err != nil {
+			warnings = append(warnings, fmt.Sprintf("upstream %q is not reachable: %v", cache.Upstream, err))
+		}
+	}
+
+	return warnings
+}
+
+// probeUpstream performs a GET on the /v2/ endpoint of the given registry URL. Any HTTP
+// response below 500 counts as reachable; notably the 401 of a registry requiring
+// authentication still proves that a registry is served there.
+func probeUpstream(ctx context.Context, httpClient *http.Client, registryURL string) error {
+	ctx, cancel := context.WithTimeout(ctx, upstreamProbeTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, registryURL+"/v2/", nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("registry responded with status %d", response.StatusCode)
+	}
+
+	return nil
+}
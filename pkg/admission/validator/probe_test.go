@@ -0,0 +1,102 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gardener/gardener/pkg/apis/core"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/install"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/controller"
+)
+
+var _ = Describe("Probe", func() {
+	var server *httptest.Server
+
+	AfterEach(func() {
+		if server != nil {
+			server.Close()
+			server = nil
+		}
+	})
+
+	It("should treat a responding registry as reachable", func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/v2/"))
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		Expect(probeUpstream(context.Background(), server.Client(), server.URL)).To(Succeed())
+	})
+
+	It("should treat a registry requiring authentication as reachable", func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+
+		Expect(probeUpstream(context.Background(), server.Client(), server.URL)).To(Succeed())
+	})
+
+	It("should report a registry responding with a server error", func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+
+		Expect(probeUpstream(context.Background(), server.Client(), server.URL)).To(MatchError(ContainSubstring("status 503")))
+	})
+
+	It("should turn failed probes into warnings", func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+
+		scheme := runtime.NewScheme()
+		Expect(install.AddToScheme(scheme)).To(Succeed())
+		handler := &probeWarningHandler{httpClient: server.Client()}
+		Expect(handler.InjectScheme(scheme)).To(Succeed())
+
+		upstream := strings.TrimPrefix(server.URL, "http://")
+		providerConfig := fmt.Sprintf(`{"apiVersion":"registry.extensions.gardener.cloud/v1alpha1","kind":"RegistryConfig","probeUpstreams":true,"caches":[{"upstream":%q,"insecure":true}]}`, upstream)
+		shoot := &core.Shoot{
+			Spec: core.ShootSpec{
+				Extensions: []core.Extension{{
+					Type:           controller.Type,
+					ProviderConfig: &runtime.RawExtension{Raw: []byte(providerConfig)},
+				}},
+			},
+		}
+
+		Expect(handler.probeWarnings(context.Background(), shoot)).To(ConsistOf(
+			ContainSubstring("is not reachable"),
+		))
+	})
+
+	It("should report an unreachable registry", func() {
+		server = httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		url := server.URL
+		server.Close()
+		server = nil
+
+		Expect(probeUpstream(context.Background(), http.DefaultClient, url)).To(HaveOccurred())
+	})
+})
@@ -85,6 +85,29 @@ func (s *shoot) Validate(ctx context.Context, new, old client.Object) error {
 		return err
 	}
 
-	return validation.ValidateRegistryConfig(registryConfig, providerConfigPath).ToAggregate()
+	if err := validation.ValidateRegistryConfig(registryConfig, providerConfigPath).ToAggregate(); err != nil {
+		return err
+	}
+
+	if oldShoot, ok := old.(*core.Shoot); ok {
+		for _, ex := range oldShoot.Spec.Extensions {
+			if ex.Type != controller.Type || ex.ProviderConfig == nil {
+				continue
+			}
+			// the applied config was accepted in the past; if it no longer decodes, the
+			// shrink check is skipped rather than blocking the update
+			oldRegistryConfig, err := decodeRegistryConfig(s.decoder, ex.ProviderConfig, providerConfigPath)
+			if err == nil {
+				if err := validation.ValidateRegistryConfigUpdate(registryConfig, oldRegistryConfig, providerConfigPath).ToAggregate(); err != nil {
+					return err
+				}
+			}
+			break
+		}
+	}
 
+	// the upstream reachability probes are performed by probeWarningHandler on the response of
+	// this validator, because a validator can only deny a request, not attach warnings to it
+
+	return nil
 }
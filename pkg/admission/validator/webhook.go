@@ -15,6 +15,8 @@
 package validator
 
 import (
+	"net/http"
+
 	"github.com/gardener/gardener-extension-registry-cache/pkg/controller"
 
 	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
@@ -36,7 +38,7 @@ var logger = log.Log.WithName("registry-cache-validator-webhook")
 func New(mgr manager.Manager) (*extensionswebhook.Webhook, error) {
 	logger.Info("Setting up webhook", "name", Name)
 
-	return extensionswebhook.New(mgr, extensionswebhook.Args{
+	webhook, err := extensionswebhook.New(mgr, extensionswebhook.Args{
 		Provider: controller.Type,
 		Name:     Name,
 		Path:     "/webhooks/validate",
@@ -44,4 +46,16 @@ func New(mgr manager.Manager) (*extensionswebhook.Webhook, error) {
 			NewShootValidator(): {{Obj: &core.Shoot{}}},
 		},
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the upstream reachability probes run on top of the generated handler, so that their
+	// results can be attached to the admission response as warnings, see probeWarningHandler
+	webhook.Webhook.Handler = &probeWarningHandler{
+		delegate:   webhook.Webhook.Handler,
+		httpClient: &http.Client{},
+	}
+
+	return webhook, nil
 }
@@ -28,4 +28,107 @@ type Configuration struct {
 
 	// HealthCheckConfig is the config for the health check controller.
 	HealthCheckConfig *apisconfig.HealthCheckConfig
+	// Monitoring is the config for the monitoring objects.
+	Monitoring *MonitoringConfig
+	// PVCPruning is the config for the pruning of orphaned cache volume claims.
+	PVCPruning *PVCPruningConfig
+	// DefaultCaches enables a default set of caches on shoots carrying a configured label.
+	DefaultCaches *DefaultCachesConfig
+	// RateLimiter tunes the workqueue rate limiter of the extension controller to protect the
+	// seed under heavy reconcile churn. If not set, the controller-runtime default rate
+	// limiter is used.
+	RateLimiter *RateLimiterConfig
+	// MaxConcurrentReconciles is the number of Extension resources the controller reconciles in
+	// parallel. Defaults to the controller-runtime default of 1.
+	MaxConcurrentReconciles *int32
+	// DegradedRestartThreshold is the number of restarts of a cache pod after which the cache
+	// is reported as degraded via a condition on the Extension resource. Defaults to 5.
+	DegradedRestartThreshold *int32
+	// DisableMirrorInjection stops injecting the containerd mirror configuration into the
+	// shoot nodes while keeping the caches deployed. It serves as a kill switch to quickly
+	// stop nodes from pulling through the caches, e.g. when a cache serves bad content.
+	DisableMirrorInjection bool
+}
+
+// PVCPruningConfig controls the pruning of cache volume claims whose cache has been removed
+// from the registry configuration.
+type PVCPruningConfig struct {
+	// TTL is the grace period an orphaned cache volume claim is kept before it is deleted.
+	// Defaults to 72h.
+	TTL *metav1.Duration
+}
+
+// DefaultCachesConfig enables a default set of caches on shoots selected by a label, e.g. for
+// a landscape-wide rollout of a Docker Hub cache. A cache explicitly configured by the shoot
+// takes precedence over the default cache for the same upstream.
+type DefaultCachesConfig struct {
+	// ShootLabel is the label a shoot must carry to get the default caches applied.
+	ShootLabel string
+	// ShootLabelValue optionally restricts the selection to shoots whose label carries this
+	// value. If not set, any value of the label selects the shoot.
+	ShootLabelValue *string
+	// Upstreams are the upstream registries a cache with default settings is created for on
+	// the selected shoots.
+	Upstreams []string
+}
+
+// RateLimiterConfig tunes the workqueue rate limiter of the extension controller. Failing
+// items are retried with exponential backoff between BaseDelay and MaxDelay, while the overall
+// reconcile rate is bounded by a token bucket of QPS and Burst.
+type RateLimiterConfig struct {
+	// BaseDelay is the initial retry delay of a failing item. Defaults to 5ms.
+	BaseDelay *metav1.Duration
+	// MaxDelay is the upper bound of the per-item retry delay. Defaults to 1000s.
+	MaxDelay *metav1.Duration
+	// QPS is the overall number of reconciles admitted per second. Defaults to 10.
+	QPS *float32
+	// Burst is the number of reconciles that may exceed the QPS momentarily. Defaults to 100.
+	Burst *int32
+}
+
+// MonitoringConfig contains settings for the monitoring objects deployed for the registry caches.
+type MonitoringConfig struct {
+	// Namespace is the namespace the monitoring objects are deployed to. If not set, the
+	// control plane namespace of the shoot is used.
+	Namespace *string
+	// DropMetricLabels is a list of label name regexes that are dropped from the scraped
+	// metrics before ingestion to limit their cardinality.
+	DropMetricLabels []string
+	// MetricsAuthSecretName is the name of a Secret in the monitoring namespace holding the
+	// bearer token (under the "token" key) presented when scraping the metrics endpoint. If not
+	// set, the metrics endpoint is scraped without authentication.
+	MetricsAuthSecretName *string
+	// HonorTimestamps controls the honor_timestamps setting of the generated scrape config.
+	// Disabling it stamps the samples with the scrape time instead of the timestamps exposed
+	// by the metrics endpoint, which avoids gaps when the scrape through the apiserver proxy
+	// is slow. Expressions extrapolating a trend from the samples, such as predict_linear in
+	// volume-full predictions, then see evenly spaced samples. If not set, the Prometheus
+	// default (true) applies.
+	HonorTimestamps *bool
+	// ScrapeTimeout is the per-scrape timeout of the generated scrape config. Raising it keeps
+	// slow scrapes through the apiserver proxy from being cut off and marked stale. If not
+	// set, the global Prometheus scrape timeout applies.
+	ScrapeTimeout *metav1.Duration
+	// AlertLabels are additional labels merged into the labels of the generated alerts, e.g.
+	// for custom alert routing. Labels required for the Gardener federation ("type" and
+	// "visibility") cannot be overridden.
+	AlertLabels map[string]string
+	// ExportDir is a directory the generated monitoring objects are written to as files
+	// instead of being created in the cluster, e.g. for teams managing their monitoring via
+	// GitOps. If not set, the objects are created in the cluster.
+	ExportDir *string
+	// AlertRetention tunes the "for" and "keep_firing_for" clauses of the generated alerts per
+	// alert name, e.g. so that an alert does not rapidly resolve and re-fire while the watched
+	// value oscillates around its threshold.
+	AlertRetention map[string]AlertRetention
+}
+
+// AlertRetention tunes for how long an alert condition must hold before the alert fires and
+// for how long a firing alert is kept firing after its condition cleared.
+type AlertRetention struct {
+	// For is the duration the alert condition must hold before the alert fires.
+	For *metav1.Duration
+	// KeepFiringFor is the duration a firing alert is kept firing after its condition cleared.
+	// It requires a Prometheus version that supports the "keep_firing_for" clause.
+	KeepFiringFor *metav1.Duration
 }
@@ -27,6 +27,7 @@ import (
 	config "github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
 	apisconfig "github.com/gardener/gardener/extensions/pkg/apis/config"
 	configv1alpha1 "github.com/gardener/gardener/extensions/pkg/apis/config/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -38,6 +39,56 @@ func init() {
 // RegisterConversions adds conversion functions to the given scheme.
 // Public to allow building arbitrary schemes.
 func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*AlertRetention)(nil), (*config.AlertRetention)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_AlertRetention_To_config_AlertRetention(a.(*AlertRetention), b.(*config.AlertRetention), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.AlertRetention)(nil), (*AlertRetention)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_AlertRetention_To_v1alpha1_AlertRetention(a.(*config.AlertRetention), b.(*AlertRetention), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*DefaultCachesConfig)(nil), (*config.DefaultCachesConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_DefaultCachesConfig_To_config_DefaultCachesConfig(a.(*DefaultCachesConfig), b.(*config.DefaultCachesConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.DefaultCachesConfig)(nil), (*DefaultCachesConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_DefaultCachesConfig_To_v1alpha1_DefaultCachesConfig(a.(*config.DefaultCachesConfig), b.(*DefaultCachesConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*MonitoringConfig)(nil), (*config.MonitoringConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_MonitoringConfig_To_config_MonitoringConfig(a.(*MonitoringConfig), b.(*config.MonitoringConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.MonitoringConfig)(nil), (*MonitoringConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_MonitoringConfig_To_v1alpha1_MonitoringConfig(a.(*config.MonitoringConfig), b.(*MonitoringConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*PVCPruningConfig)(nil), (*config.PVCPruningConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PVCPruningConfig_To_config_PVCPruningConfig(a.(*PVCPruningConfig), b.(*config.PVCPruningConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.PVCPruningConfig)(nil), (*PVCPruningConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_PVCPruningConfig_To_v1alpha1_PVCPruningConfig(a.(*config.PVCPruningConfig), b.(*PVCPruningConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*RateLimiterConfig)(nil), (*config.RateLimiterConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_RateLimiterConfig_To_config_RateLimiterConfig(a.(*RateLimiterConfig), b.(*config.RateLimiterConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.RateLimiterConfig)(nil), (*RateLimiterConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_RateLimiterConfig_To_v1alpha1_RateLimiterConfig(a.(*config.RateLimiterConfig), b.(*RateLimiterConfig), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*Configuration)(nil), (*config.Configuration)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_Configuration_To_config_Configuration(a.(*Configuration), b.(*config.Configuration), scope)
 	}); err != nil {
@@ -51,8 +102,37 @@ func RegisterConversions(s *runtime.Scheme) error {
 	return nil
 }
 
+func autoConvert_v1alpha1_AlertRetention_To_config_AlertRetention(in *AlertRetention, out *config.AlertRetention, s conversion.Scope) error {
+	out.For = (*v1.Duration)(unsafe.Pointer(in.For))
+	out.KeepFiringFor = (*v1.Duration)(unsafe.Pointer(in.KeepFiringFor))
+	return nil
+}
+
+// Convert_v1alpha1_AlertRetention_To_config_AlertRetention is an autogenerated conversion function.
+func Convert_v1alpha1_AlertRetention_To_config_AlertRetention(in *AlertRetention, out *config.AlertRetention, s conversion.Scope) error {
+	return autoConvert_v1alpha1_AlertRetention_To_config_AlertRetention(in, out, s)
+}
+
+func autoConvert_config_AlertRetention_To_v1alpha1_AlertRetention(in *config.AlertRetention, out *AlertRetention, s conversion.Scope) error {
+	out.For = (*v1.Duration)(unsafe.Pointer(in.For))
+	out.KeepFiringFor = (*v1.Duration)(unsafe.Pointer(in.KeepFiringFor))
+	return nil
+}
+
+// Convert_config_AlertRetention_To_v1alpha1_AlertRetention is an autogenerated conversion function.
+func Convert_config_AlertRetention_To_v1alpha1_AlertRetention(in *config.AlertRetention, out *AlertRetention, s conversion.Scope) error {
+	return autoConvert_config_AlertRetention_To_v1alpha1_AlertRetention(in, out, s)
+}
+
 func autoConvert_v1alpha1_Configuration_To_config_Configuration(in *Configuration, out *config.Configuration, s conversion.Scope) error {
 	out.HealthCheckConfig = (*apisconfig.HealthCheckConfig)(unsafe.Pointer(in.HealthCheckConfig))
+	out.Monitoring = (*config.MonitoringConfig)(unsafe.Pointer(in.Monitoring))
+	out.PVCPruning = (*config.PVCPruningConfig)(unsafe.Pointer(in.PVCPruning))
+	out.DefaultCaches = (*config.DefaultCachesConfig)(unsafe.Pointer(in.DefaultCaches))
+	out.RateLimiter = (*config.RateLimiterConfig)(unsafe.Pointer(in.RateLimiter))
+	out.MaxConcurrentReconciles = (*int32)(unsafe.Pointer(in.MaxConcurrentReconciles))
+	out.DegradedRestartThreshold = (*int32)(unsafe.Pointer(in.DegradedRestartThreshold))
+	out.DisableMirrorInjection = in.DisableMirrorInjection
 	return nil
 }
 
@@ -63,6 +143,13 @@ func Convert_v1alpha1_Configuration_To_config_Configuration(in *Configuration, o
 
 func autoConvert_config_Configuration_To_v1alpha1_Configuration(in *config.Configuration, out *Configuration, s conversion.Scope) error {
 	out.HealthCheckConfig = (*configv1alpha1.HealthCheckConfig)(unsafe.Pointer(in.HealthCheckConfig))
+	out.Monitoring = (*MonitoringConfig)(unsafe.Pointer(in.Monitoring))
+	out.PVCPruning = (*PVCPruningConfig)(unsafe.Pointer(in.PVCPruning))
+	out.DefaultCaches = (*DefaultCachesConfig)(unsafe.Pointer(in.DefaultCaches))
+	out.RateLimiter = (*RateLimiterConfig)(unsafe.Pointer(in.RateLimiter))
+	out.MaxConcurrentReconciles = (*int32)(unsafe.Pointer(in.MaxConcurrentReconciles))
+	out.DegradedRestartThreshold = (*int32)(unsafe.Pointer(in.DegradedRestartThreshold))
+	out.DisableMirrorInjection = in.DisableMirrorInjection
 	return nil
 }
 
@@ -70,3 +157,107 @@ func autoConvert_config_Configuration_To_v1alpha1_Configuration(in *config.Confi
 func Convert_config_Configuration_To_v1alpha1_Configuration(in *config.Configuration, out *Configuration, s conversion.Scope) error {
 	return autoConvert_config_Configuration_To_v1alpha1_Configuration(in, out, s)
 }
+
+func autoConvert_v1alpha1_DefaultCachesConfig_To_config_DefaultCachesConfig(in *DefaultCachesConfig, out *config.DefaultCachesConfig, s conversion.Scope) error {
+	out.ShootLabel = in.ShootLabel
+	out.ShootLabelValue = (*string)(unsafe.Pointer(in.ShootLabelValue))
+	out.Upstreams = *(*[]string)(unsafe.Pointer(&in.Upstreams))
+	return nil
+}
+
+// Convert_v1alpha1_DefaultCachesConfig_To_config_DefaultCachesConfig is an autogenerated conversion function.
+func Convert_v1alpha1_DefaultCachesConfig_To_config_DefaultCachesConfig(in *DefaultCachesConfig, out *config.DefaultCachesConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha1_DefaultCachesConfig_To_config_DefaultCachesConfig(in, out, s)
+}
+
+func autoConvert_config_DefaultCachesConfig_To_v1alpha1_DefaultCachesConfig(in *config.DefaultCachesConfig, out *DefaultCachesConfig, s conversion.Scope) error {
+	out.ShootLabel = in.ShootLabel
+	out.ShootLabelValue = (*string)(unsafe.Pointer(in.ShootLabelValue))
+	out.Upstreams = *(*[]string)(unsafe.Pointer(&in.Upstreams))
+	return nil
+}
+
+// Convert_config_DefaultCachesConfig_To_v1alpha1_DefaultCachesConfig is an autogenerated conversion function.
+func Convert_config_DefaultCachesConfig_To_v1alpha1_DefaultCachesConfig(in *config.DefaultCachesConfig, out *DefaultCachesConfig, s conversion.Scope) error {
+	return autoConvert_config_DefaultCachesConfig_To_v1alpha1_DefaultCachesConfig(in, out, s)
+}
+
+func autoConvert_v1alpha1_MonitoringConfig_To_config_MonitoringConfig(in *MonitoringConfig, out *config.MonitoringConfig, s conversion.Scope) error {
+	out.Namespace = (*string)(unsafe.Pointer(in.Namespace))
+	out.DropMetricLabels = *(*[]string)(unsafe.Pointer(&in.DropMetricLabels))
+	out.MetricsAuthSecretName = (*string)(unsafe.Pointer(in.MetricsAuthSecretName))
+	out.HonorTimestamps = (*bool)(unsafe.Pointer(in.HonorTimestamps))
+	out.ScrapeTimeout = (*v1.Duration)(unsafe.Pointer(in.ScrapeTimeout))
+	out.AlertLabels = *(*map[string]string)(unsafe.Pointer(&in.AlertLabels))
+	out.ExportDir = (*string)(unsafe.Pointer(in.ExportDir))
+	out.AlertRetention = *(*map[string]config.AlertRetention)(unsafe.Pointer(&in.AlertRetention))
+	return nil
+}
+
+// Convert_v1alpha1_MonitoringConfig_To_config_MonitoringConfig is an autogenerated conversion function.
+func Convert_v1alpha1_MonitoringConfig_To_config_MonitoringConfig(in *MonitoringConfig, out *config.MonitoringConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha1_MonitoringConfig_To_config_MonitoringConfig(in, out, s)
+}
+
+func autoConvert_config_MonitoringConfig_To_v1alpha1_MonitoringConfig(in *config.MonitoringConfig, out *MonitoringConfig, s conversion.Scope) error {
+	out.Namespace = (*string)(unsafe.Pointer(in.Namespace))
+	out.DropMetricLabels = *(*[]string)(unsafe.Pointer(&in.DropMetricLabels))
+	out.MetricsAuthSecretName = (*string)(unsafe.Pointer(in.MetricsAuthSecretName))
+	out.HonorTimestamps = (*bool)(unsafe.Pointer(in.HonorTimestamps))
+	out.ScrapeTimeout = (*v1.Duration)(unsafe.Pointer(in.ScrapeTimeout))
+	out.AlertLabels = *(*map[string]string)(unsafe.Pointer(&in.AlertLabels))
+	out.ExportDir = (*string)(unsafe.Pointer(in.ExportDir))
+	out.AlertRetention = *(*map[string]AlertRetention)(unsafe.Pointer(&in.AlertRetention))
+	return nil
+}
+
+// Convert_config_MonitoringConfig_To_v1alpha1_MonitoringConfig is an autogenerated conversion function.
+func Convert_config_MonitoringConfig_To_v1alpha1_MonitoringConfig(in *config.MonitoringConfig, out *MonitoringConfig, s conversion.Scope) error {
+	return autoConvert_config_MonitoringConfig_To_v1alpha1_MonitoringConfig(in, out, s)
+}
+
+func autoConvert_v1alpha1_PVCPruningConfig_To_config_PVCPruningConfig(in *PVCPruningConfig, out *config.PVCPruningConfig, s conversion.Scope) error {
+	out.TTL = (*v1.Duration)(unsafe.Pointer(in.TTL))
+	return nil
+}
+
+// Convert_v1alpha1_PVCPruningConfig_To_config_PVCPruningConfig is an autogenerated conversion function.
+func Convert_v1alpha1_PVCPruningConfig_To_config_PVCPruningConfig(in *PVCPruningConfig, out *config.PVCPruningConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PVCPruningConfig_To_config_PVCPruningConfig(in, out, s)
+}
+
+func autoConvert_config_PVCPruningConfig_To_v1alpha1_PVCPruningConfig(in *config.PVCPruningConfig, out *PVCPruningConfig, s conversion.Scope) error {
+	out.TTL = (*v1.Duration)(unsafe.Pointer(in.TTL))
+	return nil
+}
+
+// Convert_config_PVCPruningConfig_To_v1alpha1_PVCPruningConfig is an autogenerated conversion function.
+func Convert_config_PVCPruningConfig_To_v1alpha1_PVCPruningConfig(in *config.PVCPruningConfig, out *PVCPruningConfig, s conversion.Scope) error {
+	return autoConvert_config_PVCPruningConfig_To_v1alpha1_PVCPruningConfig(in, out, s)
+}
+
+func autoConvert_v1alpha1_RateLimiterConfig_To_config_RateLimiterConfig(in *RateLimiterConfig, out *config.RateLimiterConfig, s conversion.Scope) error {
+	out.BaseDelay = (*v1.Duration)(unsafe.Pointer(in.BaseDelay))
+	out.MaxDelay = (*v1.Duration)(unsafe.Pointer(in.MaxDelay))
+	out.QPS = (*float32)(unsafe.Pointer(in.QPS))
+	out.Burst = (*int32)(unsafe.Pointer(in.Burst))
+	return nil
+}
+
+// Convert_v1alpha1_RateLimiterConfig_To_config_RateLimiterConfig is an autogenerated conversion function.
+func Convert_v1alpha1_RateLimiterConfig_To_config_RateLimiterConfig(in *RateLimiterConfig, out *config.RateLimiterConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha1_RateLimiterConfig_To_config_RateLimiterConfig(in, out, s)
+}
+
+func autoConvert_config_RateLimiterConfig_To_v1alpha1_RateLimiterConfig(in *config.RateLimiterConfig, out *RateLimiterConfig, s conversion.Scope) error {
+	out.BaseDelay = (*v1.Duration)(unsafe.Pointer(in.BaseDelay))
+	out.MaxDelay = (*v1.Duration)(unsafe.Pointer(in.MaxDelay))
+	out.QPS = (*float32)(unsafe.Pointer(in.QPS))
+	out.Burst = (*int32)(unsafe.Pointer(in.Burst))
+	return nil
+}
+
+// Convert_config_RateLimiterConfig_To_v1alpha1_RateLimiterConfig is an autogenerated conversion function.
+func Convert_config_RateLimiterConfig_To_v1alpha1_RateLimiterConfig(in *config.RateLimiterConfig, out *RateLimiterConfig, s conversion.Scope) error {
+	return autoConvert_config_RateLimiterConfig_To_v1alpha1_RateLimiterConfig(in, out, s)
+}
@@ -15,8 +15,12 @@
 package validation
 
 import (
+	"path/filepath"
+
 	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
 
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
@@ -24,5 +28,86 @@ import (
 func ValidateConfiguration(config *config.Configuration) field.ErrorList {
 	allErrs := field.ErrorList{}
 
+	if config.Monitoring != nil && config.Monitoring.Namespace != nil {
+		for _, msg := range apivalidation.ValidateNamespaceName(*config.Monitoring.Namespace, false) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("monitoring", "namespace"), *config.Monitoring.Namespace, msg))
+		}
+	}
+
+	if config.Monitoring != nil && config.Monitoring.MetricsAuthSecretName != nil {
+		for _, msg := range apivalidation.NameIsDNSSubdomain(*config.Monitoring.MetricsAuthSecretName, false) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("monitoring", "metricsAuthSecretName"), *config.Monitoring.MetricsAuthSecretName, msg))
+		}
+	}
+
+	if config.Monitoring != nil && config.Monitoring.ExportDir != nil && !filepath.IsAbs(*config.Monitoring.ExportDir) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("monitoring", "exportDir"), *config.Monitoring.ExportDir, "exportDir must be an absolute path"))
+	}
+
+	if config.Monitoring != nil && config.Monitoring.ScrapeTimeout != nil && config.Monitoring.ScrapeTimeout.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("monitoring", "scrapeTimeout"), config.Monitoring.ScrapeTimeout.Duration.String(), "scrapeTimeout must be a duration greater than zero"))
+	}
+
+	if config.Monitoring != nil {
+		for key := range config.Monitoring.AlertLabels {
+			// "type" and "visibility" steer the Gardener alert federation and must stay intact
+			if key == "type" || key == "visibility" {
+				allErrs = append(allErrs, field.Forbidden(field.NewPath("monitoring", "alertLabels").Key(key), "labels required for the Gardener federation cannot be overridden"))
+			}
+		}
+
+		for alert, retention := range config.Monitoring.AlertRetention {
+			if retention.For != nil && retention.For.Duration <= 0 {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("monitoring", "alertRetention").Key(alert).Child("for"), retention.For.Duration.String(), "for must be a duration greater than zero"))
+			}
+			if retention.KeepFiringFor != nil && retention.KeepFiringFor.Duration <= 0 {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("monitoring", "alertRetention").Key(alert).Child("keepFiringFor"), retention.KeepFiringFor.Duration.String(), "keepFiringFor must be a duration greater than zero"))
+			}
+		}
+	}
+
+	if config.DefaultCaches != nil {
+		if config.DefaultCaches.ShootLabel == "" {
+			allErrs = append(allErrs, field.Required(field.NewPath("defaultCaches", "shootLabel"), "shootLabel is required"))
+		} else {
+			for _, msg := range utilvalidation.IsQualifiedName(config.DefaultCaches.ShootLabel) {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("defaultCaches", "shootLabel"), config.DefaultCaches.ShootLabel, msg))
+			}
+		}
+		if len(config.DefaultCaches.Upstreams) == 0 {
+			allErrs = append(allErrs, field.Required(field.NewPath("defaultCaches", "upstreams"), "at least one upstream is required"))
+		}
+	}
+
+	if config.PVCPruning != nil && config.PVCPruning.TTL != nil && config.PVCPruning.TTL.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("pvcPruning", "ttl"), config.PVCPruning.TTL.Duration.String(), "ttl must be a duration greater than zero"))
+	}
+
+	if rl := config.RateLimiter; rl != nil {
+		if rl.BaseDelay != nil && rl.BaseDelay.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("rateLimiter", "baseDelay"), rl.BaseDelay.Duration.String(), "baseDelay must be a duration greater than zero"))
+		}
+		if rl.MaxDelay != nil && rl.MaxDelay.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("rateLimiter", "maxDelay"), rl.MaxDelay.Duration.String(), "maxDelay must be a duration greater than zero"))
+		}
+		if rl.BaseDelay != nil && rl.MaxDelay != nil && rl.MaxDelay.Duration < rl.BaseDelay.Duration {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("rateLimiter", "maxDelay"), rl.MaxDelay.Duration.String(), "maxDelay must not be smaller than baseDelay"))
+		}
+		if rl.QPS != nil && *rl.QPS <= 0 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("rateLimiter", "qps"), *rl.QPS, "qps must be greater than zero"))
+		}
+		if rl.Burst != nil && *rl.Burst <= 0 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("rateLimiter", "burst"), *rl.Burst, "burst must be greater than zero"))
+		}
+	}
+
+	if config.MaxConcurrentReconciles != nil && *config.MaxConcurrentReconciles <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("maxConcurrentReconciles"), *config.MaxConcurrentReconciles, "maxConcurrentReconciles must be greater than zero"))
+	}
+
+	if config.DegradedRestartThreshold != nil && *config.DegradedRestartThreshold <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("degradedRestartThreshold"), *config.DegradedRestartThreshold, "degradedRestartThreshold must be greater than zero"))
+	}
+
 	return allErrs
 }
@@ -15,14 +15,18 @@
 package validation_test
 
 import (
+	"time"
+
 	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
 	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config/validation"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
-
-	// . "github.com/onsi/gomega/gstruct"
+	. "github.com/onsi/gomega/gstruct"
 	gomegatypes "github.com/onsi/gomega/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
 )
 
 var _ = Describe("Validation", func() {
@@ -31,5 +35,203 @@ var _ = Describe("Validation", func() {
 			err := validation.ValidateConfiguration(&config)
 			Expect(err).To(match)
 		},
+		Entry("empty configuration", config.Configuration{}, BeEmpty()),
+		Entry("valid alert labels",
+			config.Configuration{
+				Monitoring: &config.MonitoringConfig{
+					AlertLabels: map[string]string{"team": "platform", "severity": "critical"},
+				},
+			},
+			BeEmpty(),
+		),
+		Entry("valid monitoring export directory",
+			config.Configuration{
+				Monitoring: &config.MonitoringConfig{ExportDir: pointer.String("/var/lib/monitoring-export")},
+			},
+			BeEmpty(),
+		),
+		Entry("relative monitoring export directory",
+			config.Configuration{
+				Monitoring: &config.MonitoringConfig{ExportDir: pointer.String("monitoring-export")},
+			},
+			ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("monitoring.exportDir"),
+				})),
+			),
+		),
+		Entry("valid scrape timeout",
+			config.Configuration{
+				Monitoring: &config.MonitoringConfig{ScrapeTimeout: &metav1.Duration{Duration: 30 * time.Second}},
+			},
+			BeEmpty(),
+		),
+		Entry("non-positive scrape timeout",
+			config.Configuration{
+				Monitoring: &config.MonitoringConfig{ScrapeTimeout: &metav1.Duration{Duration: -time.Second}},
+			},
+			ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("monitoring.scrapeTimeout"),
+				})),
+			),
+		),
+		Entry("valid alert retention",
+			config.Configuration{
+				Monitoring: &config.MonitoringConfig{
+					AlertRetention: map[string]config.AlertRetention{
+						"RegistryCacheServingStaleContent": {
+							For:           &metav1.Duration{Duration: 30 * time.Minute},
+							KeepFiringFor: &metav1.Duration{Duration: 10 * time.Minute},
+						},
+					},
+				},
+			},
+			BeEmpty(),
+		),
+		Entry("non-positive alert retention durations",
+			config.Configuration{
+				Monitoring: &config.MonitoringConfig{
+					AlertRetention: map[string]config.AlertRetention{
+						"RegistryCacheServingStaleContent": {
+							For:           &metav1.Duration{},
+							KeepFiringFor: &metav1.Duration{Duration: -time.Minute},
+						},
+					},
+				},
+			},
+			ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("monitoring.alertRetention[RegistryCacheServingStaleContent].for"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("monitoring.alertRetention[RegistryCacheServingStaleContent].keepFiringFor"),
+				})),
+			),
+		),
+		Entry("valid default caches",
+			config.Configuration{
+				DefaultCaches: &config.DefaultCachesConfig{
+					ShootLabel: "registry-cache.extensions.gardener.cloud/default-caches",
+					Upstreams:  []string{"docker.io"},
+				},
+			},
+			BeEmpty(),
+		),
+		Entry("incomplete default caches",
+			config.Configuration{
+				DefaultCaches: &config.DefaultCachesConfig{},
+			},
+			ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeRequired),
+					"Field": Equal("defaultCaches.shootLabel"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeRequired),
+					"Field": Equal("defaultCaches.upstreams"),
+				})),
+			),
+		),
+		Entry("invalid default caches shoot label",
+			config.Configuration{
+				DefaultCaches: &config.DefaultCachesConfig{
+					ShootLabel: "invalid label",
+					Upstreams:  []string{"docker.io"},
+				},
+			},
+			ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("defaultCaches.shootLabel"),
+				})),
+			),
+		),
+		Entry("valid rate limiter",
+			config.Configuration{
+				RateLimiter: &config.RateLimiterConfig{
+					BaseDelay: &metav1.Duration{Duration: 10 * time.Millisecond},
+					MaxDelay:  &metav1.Duration{Duration: 5 * time.Minute},
+					QPS:       pointer.Float32(5),
+					Burst:     pointer.Int32(50),
+				},
+			},
+			BeEmpty(),
+		),
+		Entry("non-positive rate limiter parameters",
+			config.Configuration{
+				RateLimiter: &config.RateLimiterConfig{
+					BaseDelay: &metav1.Duration{Duration: -time.Second},
+					QPS:       pointer.Float32(0),
+					Burst:     pointer.Int32(0),
+				},
+			},
+			ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("rateLimiter.baseDelay"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("rateLimiter.qps"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("rateLimiter.burst"),
+				})),
+			),
+		),
+		Entry("max delay smaller than base delay",
+			config.Configuration{
+				RateLimiter: &config.RateLimiterConfig{
+					BaseDelay: &metav1.Duration{Duration: time.Minute},
+					MaxDelay:  &metav1.Duration{Duration: time.Second},
+				},
+			},
+			ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("rateLimiter.maxDelay"),
+				})),
+			),
+		),
+		Entry("valid max concurrent reconciles",
+			config.Configuration{
+				MaxConcurrentReconciles: pointer.Int32(5),
+			},
+			BeEmpty(),
+		),
+		Entry("non-positive max concurrent reconciles",
+			config.Configuration{
+				MaxConcurrentReconciles: pointer.Int32(0),
+			},
+			ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("maxConcurrentReconciles"),
+				})),
+			),
+		),
+		Entry("forbidden alert labels",
+			config.Configuration{
+				Monitoring: &config.MonitoringConfig{
+					AlertLabels: map[string]string{"type": "seed", "visibility": "operator"},
+				},
+			},
+			ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("monitoring.alertLabels[type]"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("monitoring.alertLabels[visibility]"),
+				})),
+			),
+		),
 	)
 })
@@ -15,6 +15,7 @@
 package registry
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -27,14 +28,460 @@ type RegistryConfig struct {
 
 	// Caches is a slice of registry cache to deploy
 	Caches []RegistryCache
+	// SuggestCaches enables inspecting the images of the shoot's running pods and reporting
+	// the most-used upstream registries without a configured cache as suggestions in the
+	// provider status.
+	SuggestCaches *bool
+	// AutoCreateCaches additionally deploys a cache with default settings for every suggested
+	// upstream. It requires SuggestCaches to be enabled.
+	AutoCreateCaches *bool
+	// CacheSystemImages additionally deploys a cache with default settings for every known
+	// Gardener system registry that images of the shoot's system components are pulled from,
+	// speeding up node bootstrap.
+	CacheSystemImages *bool
+	// Namespace is the shoot namespace the caches are deployed to. Defaults to
+	// "registry-cache". It must not be a system namespace.
+	Namespace *string
+	// Quota is the total volume storage all caches together may request, enforced via a
+	// ResourceQuota in the cache namespace. If not set, no quota is created.
+	Quota *resource.Quantity
+	// MirrorConfigFormat is the format the containerd mirror configuration is rendered in on
+	// the shoot nodes. Supported values are "inline" (mirror entries in a config.toml drop-in)
+	// and "hosts" (hosts.toml drop-ins under the registry config_path). If not set, the format
+	// is detected on each node from its containerd version.
+	MirrorConfigFormat *string
+	// ProbeUpstreams enables a best-effort reachability probe of the configured upstreams at
+	// admission time to catch typos early. An unreachable upstream is only reported as a
+	// warning and never blocks the request.
+	ProbeUpstreams *bool
+	// StorageTiering selects the StorageClass of each cache from its observed traffic and
+	// migrates cache volumes between the tiers. If not set, all caches use the storage class
+	// they are configured with (or the cluster default).
+	StorageTiering *StorageTiering
+	// InShootMonitoring additionally generates a PodMonitor in the cache namespace, so that a
+	// prometheus-operator managed monitoring stack running inside the shoot scrapes the cache
+	// metrics as well. If not set, the metrics are only scraped by the seed Prometheus.
+	InShootMonitoring *InShootMonitoring
+	// Ingress exposes all caches via a single path-based Ingress in the cache namespace. Each
+	// cache is served under its ingressPathPrefix; the ingress controller strips the prefix
+	// again, so that "/<prefix>/v2/..." routes to the plain registry API of the right cache.
+	// It requires an ingress-nginx controller in the shoot, the generated rewrite annotations
+	// are specific to it.
+	Ingress *Ingress
+}
+
+// Ingress configures the shared Ingress in front of all caches.
+type Ingress struct {
+	// Host is the host all caches are served under.
+	Host string
+	// ClassName is the IngressClass of the generated Ingress. If not set, the default ingress
+	// class of the shoot is used.
+	ClassName *string
+}
+
+// InShootMonitoring configures the monitoring resources generated for an in-shoot monitoring
+// stack.
+type InShootMonitoring struct {
+	// Labels are additional labels of the generated PodMonitor, so that it can be matched by
+	// the podMonitorSelector of the in-shoot Prometheus.
+	Labels map[string]string
+}
+
+// StorageTiering selects the StorageClass of each cache from its observed traffic, so that
+// busy caches land on fast storage while rarely used ones move to cheap storage. A changed
+// tier is applied by migrating the cache volume: the content is staged to a volume of the new
+// class, the cache is recreated on the new tier and restores the staged content on startup.
+// A cache with an explicitly configured storageClassName is never tiered automatically.
+type StorageTiering struct {
+	// HotStorageClassName is the StorageClass of caches classified as hot.
+	HotStorageClassName string
+	// ColdStorageClassName is the StorageClass of caches classified as cold.
+	ColdStorageClassName string
+	// HotBytesThreshold is the total number of bytes pulled through a cache at or above which
+	// it is classified as hot. Defaults to 10Gi.
+	HotBytesThreshold *resource.Quantity
+	// MigrationWindow is the daily time window in which cache volumes are migrated between
+	// tiers. Migrations are disruptive because the cache is recreated on the new volume; if
+	// not set, migrations may happen with any reconciliation.
+	MigrationWindow *MaintenanceWindow
 }
 
 // RegistryCache defines a registry cache to deploy
 type RegistryCache struct {
 	// Upstream is the remote registry host (and optionally port) to cache
 	Upstream string
+	// Insecure connects to the upstream via plain HTTP instead of HTTPS, e.g. for internal
+	// registries that are not reachable via TLS. It must be set explicitly to allow the
+	// insecure connection.
+	Insecure *bool
+	// PathPrefix is an optional path appended to the upstream when building the remote URL, for
+	// proxies exposing several registries under path prefixes of one host. It must start with
+	// a "/" and must not end with one.
+	PathPrefix *string
+	// IngressPathPrefix is the path prefix the cache is served under on the shared Ingress,
+	// see RegistryConfig.Ingress. It must start with a "/", must not end with one and must be
+	// unique across all caches. Defaults to "/" followed by the (sanitized) upstream.
+	IngressPathPrefix *string
 	// Size is the size of the registry cache
 	Size *resource.Quantity
+	// VolumeMode is the mode of the cache volume. Supported values are "Filesystem" and
+	// "Block". Defaults to "Filesystem".
+	VolumeMode *string
+	// Group tags the cache with a purpose (e.g. "base-images") that is propagated as a pod
+	// label and available as grouping dimension on dashboards and alerts. Must be a DNS
+	// label.
+	Group *string
+	// StorageClassName is the StorageClass of the cache volume. If not set, the cluster
+	// default is used. A configured class also pins the cache to that class under a storage
+	// tiering policy.
+	StorageClassName *string
 	// GarbageCollectionEnabled enables/disables cache garbage collection
 	GarbageCollectionEnabled *bool
+	// GarbageCollection tunes the garbage collection of the cache. It only takes effect when
+	// garbage collection is enabled.
+	GarbageCollection *GarbageCollection
+	// UploadPurging tunes the purging of stale in-progress uploads of the cache. If not set,
+	// the distribution defaults apply (purging enabled, uploads older than 168h purged every
+	// 24h).
+	UploadPurging *UploadPurging
+	// CATrustStore references an object with additional CA certificates to add to the
+	// system trust store of the registry cache.
+	CATrustStore *CATrustStore
+	// UpdateStrategy configures the update strategy of the cache StatefulSet.
+	UpdateStrategy *UpdateStrategy
+	// Autoscaling configures a HorizontalPodAutoscaler scaling the cache replicas under
+	// load. It is only supported in the "StatefulSet" mode.
+	Autoscaling *Autoscaling
+	// Mode selects how the cache is deployed. In the default "StatefulSet" mode a single cache
+	// with a persistent volume serves the whole cluster; in the "DaemonSet" mode one cache per
+	// node stores its content on the node itself and containerd pulls through localhost.
+	Mode *string
+	// HostPort is the host port under which a per-node cache is reachable as
+	// localhost:<hostPort>. It must be set for the "DaemonSet" mode and must not be set
+	// otherwise.
+	HostPort *int32
+	// ServiceAccountName is the name of the ServiceAccount the cache pods run under. It
+	// defaults to the shared "registry-cache" ServiceAccount managed by the extension.
+	ServiceAccountName *string
+	// DNSPolicy is the DNS policy of the cache pods. It defaults to the cluster default ("ClusterFirst").
+	DNSPolicy *string
+	// DNSConfig is the DNS configuration of the cache pods merged according to the DNS policy.
+	DNSConfig *corev1.PodDNSConfig
+	// ImagePullPolicy is the pull policy of the registry container. Supported values are
+	// "Always", "IfNotPresent" and "Never", defaults to "IfNotPresent".
+	ImagePullPolicy *string
+	// FSGroup is the group ID applied to the cache volume. It is required for storage backends
+	// that expect a specific group ownership, e.g. NFS or CephFS.
+	FSGroup *int64
+	// FSGroupChangePolicy defines how the ownership of the cache volume is changed to the
+	// configured FSGroup. Supported values are "OnRootMismatch" and "Always".
+	FSGroupChangePolicy *string
+	// HTTPHost is an externally-reachable address of the registry cache set as distribution's
+	// http.host, e.g. "https://registry.example.com:5000".
+	HTTPHost *string
+	// HTTP tunes the HTTP server of the registry cache.
+	HTTP *HTTP
+	// MaintenanceWindow confines non-urgent, disruptive changes to the cache (e.g. pod restarts
+	// from config changes) to a daily time window.
+	MaintenanceWindow *MaintenanceWindow
+	// LivenessProbe tunes the HTTP liveness probe of the registry container. Unlike a readiness
+	// check against the upstream, the probe only verifies that the local registry process still
+	// answers requests.
+	LivenessProbe *LivenessProbe
+	// StartupProbe tunes the HTTP startup probe of the registry container. While the startup
+	// probe has not succeeded yet the liveness probe is held off, so the filesystem scan of a
+	// large cache volume can complete without the liveness probe restarting the container.
+	StartupProbe *StartupProbe
+	// ExtraConfig is a raw YAML fragment that is deep-merged into the generated distribution
+	// config.yml. Fields managed by the extension (e.g. the storage root directory and the HTTP
+	// address) take precedence over the fragment and must not be set here.
+	ExtraConfig *string
+	// PreferredAffinity makes the cache pod prefer nodes running the selected workload pods, so
+	// that pulls are served from a co-located cache where possible.
+	PreferredAffinity *PreferredAffinity
+	// NodeAffinity constrains the nodes the cache pod can be scheduled on, e.g. to keep the
+	// cache on the worker pool whose nodes pull through it. For preferring nodes running
+	// selected workload pods see PreferredAffinity.
+	NodeAffinity *corev1.NodeAffinity
+	// FallbackToUpstream controls whether the upstream itself is added as a secondary mirror
+	// endpoint, so that pulls still succeed directly if the cache is down. Defaults to true.
+	FallbackToUpstream *bool
+	// Proxy tunes how the registry cache talks to its upstream.
+	Proxy *Proxy
+	// Schema1Compatibility toggles serving the long-deprecated Docker schema 1 image
+	// manifests, mapped to "compatibility.schema1" of the registry configuration. Schema 1
+	// has been deprecated since Docker 1.10, but some legacy images can still only be pulled
+	// with the compatibility enabled. If not set, the registry default is used.
+	Schema1Compatibility *bool
+	// SmokeTest configures an optional post-deploy Job verifying end-to-end caching.
+	SmokeTest *SmokeTest
+	// Service tunes the Service in front of the registry cache pods.
+	Service *Service
+	// Debug tunes the debug endpoint of the registry cache.
+	Debug *Debug
+	// CompressionPolicy controls recompression of cached layers. Layers are content addressed
+	// and clients verify the digest of everything they pull, so stored layers can never be
+	// recompressed transparently. Supported values are therefore "None" (default) and
+	// "ReportOnly", which adds a sidecar that only reports the size of the blob store as an
+	// upper bound for the savings of a zstd recompression without modifying any content.
+	CompressionPolicy *string
+	// Storage tunes the storage driver of the registry cache.
+	Storage *Storage
+	// UpstreamReadiness makes the cache pod report NotReady while its upstream cannot be
+	// reached, so that the Service stops routing to it and containerd falls through to pulling
+	// directly. Without it the pod stays ready and keeps serving (possibly stale) cached
+	// content while the upstream is down.
+	UpstreamReadiness *bool
+	// ResourceProfile selects preset resource requests and limits for the registry container.
+	// Supported values are "small", "medium" and "large". For full control over the resources
+	// use Resources instead.
+	ResourceProfile *string
+	// Resources are explicit resource requests and limits of the registry container. It is
+	// mutually exclusive with ResourceProfile.
+	Resources *corev1.ResourceRequirements
+	// PodManagementPolicy is the pod management policy of the cache StatefulSet. "Parallel"
+	// starts the pods of a multi-replica cache at once instead of one after the other.
+	// Supported values are "OrderedReady" and "Parallel". Defaults to "OrderedReady".
+	PodManagementPolicy *string
+	// LongTermMetrics additionally records hourly downsampled pull statistics of this cache
+	// under the "shoot:registry_cache_pulled_bytes:1h" series. The downsampled series is cheap
+	// enough for the garden Prometheus to keep it much longer than the raw series, e.g. for
+	// multi-month capacity trending.
+	LongTermMetrics *bool
+}
+
+const (
+	// ModeStatefulSet deploys a cache as a StatefulSet with a persistent volume, serving the
+	// whole cluster through a cluster-internal service.
+	ModeStatefulSet = "StatefulSet"
+	// ModeDaemonSet deploys one cache pod per node with a hostPath volume, reachable as
+	// localhost:<hostPort> on every node.
+	ModeDaemonSet = "DaemonSet"
+
+	// MirrorConfigFormatInline renders the mirror entries inline into a config.toml drop-in.
+	MirrorConfigFormatInline = "inline"
+	// MirrorConfigFormatHosts renders the mirror entries as hosts.toml drop-ins under the
+	// registry config_path. It requires containerd >= 1.5.
+	MirrorConfigFormatHosts = "hosts"
+
+	// CompressionPolicyNone leaves the cached layers exactly as fetched from the upstream.
+	CompressionPolicyNone = "None"
+	// CompressionPolicyReportOnly reports the potential savings of a recompression without
+	// modifying any cached content.
+	CompressionPolicyReportOnly = "ReportOnly"
+
+	// ResourceProfileSmall is the resource profile for caches serving a handful of nodes.
+	ResourceProfileSmall = "small"
+	// ResourceProfileMedium is the resource profile for caches serving a mid-sized cluster.
+	ResourceProfileMedium = "medium"
+	// ResourceProfileLarge is the resource profile for caches serving a large or pull-heavy
+	// cluster.
+	ResourceProfileLarge = "large"
+)
+
+// Proxy tunes how the registry cache talks to its upstream.
+type Proxy struct {
+	// PinUpstreamIPs resolves the upstream hostname at reconcile time and pins the resolved IPs
+	// via hostAliases of the cache pod, so that fetches do not depend on DNS being available.
+	// The pinned IPs are refreshed with every reconciliation.
+	PinUpstreamIPs *bool
+}
+
+// Service tunes the Service in front of the registry cache pods.
+type Service struct {
+	// Type is the type of the Service in front of the cache pods. Supported values are
+	// "ClusterIP" and "NodePort". Defaults to "ClusterIP".
+	Type *string
+	// SessionAffinity makes pulls of one client stick to one cache replica. This improves the
+	// hit ratio of a multi-replica cache whose replicas do not share their storage. Supported
+	// values are "None" and "ClientIP". Defaults to "None".
+	SessionAffinity *string
+	// SessionAffinityTimeoutSeconds is the sticky time of the "ClientIP" affinity in seconds.
+	// Must be greater than 0 and at most 86400. Defaults to 10800.
+	SessionAffinityTimeoutSeconds *int32
+}
+
+// SmokeTest configures an optional post-deploy Job that pulls the given image through the
+// cache and asserts that the repository is served from the cache on a second pull. The Job
+// only talks to the cache service; authentication against private upstreams is handled by the
+// cache itself. It cleans up after itself via its TTL.
+type SmokeTest struct {
+	// Image is the repository (and optionally tag) of a small image in the upstream that is
+	// pulled through the cache, e.g. "library/alpine:3.16".
+	Image string
+}
+
+// PreferredAffinity selects workload pods the cache pod should preferably be scheduled close
+// to. It is rendered as preferredDuringSchedulingIgnoredDuringExecution pod affinity, i.e. it
+// never prevents the cache pod from being scheduled.
+type PreferredAffinity struct {
+	// LabelSelector selects the workload pods across all namespaces.
+	LabelSelector metav1.LabelSelector
+	// Weight is the weight of the affinity term in the range 1 to 100. Defaults to 100.
+	Weight *int32
+	// TopologyKey is the node label the co-location is based on. Defaults to
+	// "kubernetes.io/hostname".
+	TopologyKey *string
+}
+
+// LivenessProbe tunes the HTTP liveness probe of the registry container. Unset fields default
+// to the values applied by the extension.
+type LivenessProbe struct {
+	// Path is the HTTP path probed on the registry port, defaults to "/v2/".
+	Path *string
+	// InitialDelaySeconds is the delay before the first probe, defaults to 5.
+	InitialDelaySeconds *int32
+	// PeriodSeconds is the interval between probes, defaults to 10.
+	PeriodSeconds *int32
+	// TimeoutSeconds is the timeout of a single probe, defaults to 2.
+	TimeoutSeconds *int32
+	// FailureThreshold is the number of consecutive failures after which the container is
+	// restarted, defaults to 6.
+	FailureThreshold *int32
+}
+
+// StartupProbe tunes the HTTP startup probe of the registry container. The probe targets the
+// same path and port as the liveness probe.
+type StartupProbe struct {
+	// PeriodSeconds is the interval between probes, defaults to 10.
+	PeriodSeconds *int32
+	// FailureThreshold is the number of consecutive failures after which the startup is
+	// considered failed and the container is restarted, defaults to 30.
+	FailureThreshold *int32
+}
+
+// HTTP tunes the HTTP server of the registry cache.
+type HTTP struct {
+	// HTTP2 enables/disables HTTP/2 on the cache server. Disabling it can help clients that
+	// misbehave on multiplexed connections while pulling very large layers. Defaults to true.
+	HTTP2 *bool
+}
+
+// Autoscaling configures a HorizontalPodAutoscaler scaling the cache replicas under load.
+// Replicas added by the autoscaler get their own volume and fill it independently; a "ClientIP"
+// session affinity of the Service keeps clients on one replica and preserves the hit ratio,
+// see Service.
+type Autoscaling struct {
+	// MinReplicas is the lower bound of replicas. Defaults to 1.
+	MinReplicas *int32
+	// MaxReplicas is the upper bound of replicas.
+	MaxReplicas int32
+	// TargetCPUUtilization is the average CPU utilization percentage the autoscaler aims for.
+	TargetCPUUtilization *int32
+	// TargetMemoryUtilization is the average memory utilization percentage the autoscaler
+	// aims for.
+	TargetMemoryUtilization *int32
+}
+
+// MaintenanceWindow is a daily time window in the format of Gardener maintenance time windows,
+// i.e. "HHMMSS+ZZZZ" with the timezone encoded as UTC offset.
+type MaintenanceWindow struct {
+	// Begin is the beginning of the time window, e.g. "220000+0100".
+	Begin string
+	// End is the end of the time window, e.g. "230000+0100".
+	End string
+}
+
+const (
+	// UpdateStrategyTypeRollingUpdate replaces the pods of a cache one after another.
+	UpdateStrategyTypeRollingUpdate = "RollingUpdate"
+	// UpdateStrategyTypeOnDelete replaces the pods of a cache only when they are deleted manually.
+	UpdateStrategyTypeOnDelete = "OnDelete"
+)
+
+// UpdateStrategy configures the update strategy of a cache StatefulSet.
+type UpdateStrategy struct {
+	// Type is the update strategy type. Supported values are "RollingUpdate" and "OnDelete",
+	// defaults to "RollingUpdate".
+	Type *string
+	// Partition is the ordinal at which the StatefulSet is partitioned for a rolling update.
+	// It must only be set for the "RollingUpdate" type.
+	Partition *int32
+}
+
+// Debug tunes the debug endpoint of the registry cache that serves the prometheus metrics and
+// optionally the pprof profiling handlers.
+type Debug struct {
+	// Port is the port of the debug endpoint. Defaults to 5001.
+	Port *int32
+	// PprofEnabled enables the pprof profiling handlers on the debug endpoint. Profiling data
+	// exposes internals of the registry process, so this is deliberately opt-in.
+	PprofEnabled *bool
+}
+
+// GarbageCollection tunes the garbage collection of a registry cache.
+type GarbageCollection struct {
+	// DeleteUntagged also removes manifests that are no longer referenced by any tag, e.g.
+	// after a tag has been overwritten, by passing --delete-untagged to the scheduled garbage
+	// collection runs. It must not be combined with ReadOnly.
+	DeleteUntagged *bool
+	// ReadOnly puts the storage into read-only mode while the garbage collection runs.
+	ReadOnly *bool
+	// MinFreeBytes is a hard threshold of free space on the cache volume below which cache-miss
+	// fetches from the upstream are blocked instead of filling the last bytes. Already-cached
+	// content is still served: while the volume stats report less free space, the registry is
+	// kept in the read-only maintenance mode.
+	MinFreeBytes *resource.Quantity
+}
+
+// UploadPurging tunes the purging of stale in-progress uploads of a registry cache. Uploads
+// that never complete otherwise occupy space on the cache volume until purged.
+type UploadPurging struct {
+	// Enabled enables/disables the purging of stale uploads. Defaults to true.
+	Enabled *bool
+	// Age is the age after which an in-progress upload is considered stale and purged.
+	// Defaults to 168h.
+	Age *metav1.Duration
+	// Interval is the interval between purge runs. Defaults to 24h.
+	Interval *metav1.Duration
+	// DryRun only logs the uploads that would be purged without removing them.
+	DryRun *bool
+}
+
+// Storage tunes the storage driver of the registry cache.
+type Storage struct {
+	// DriverParameters are parameters passed to the filesystem storage driver of the
+	// distribution. The keys must be known driver parameters; the root directory of the driver
+	// is managed by the extension and cannot be overridden.
+	DriverParameters map[string]string
+}
+
+// CATrustStore references a ConfigMap or Secret in the registry-cache namespace of the shoot
+// whose entries are added to the system trust store of the registry cache. Exactly one of the
+// fields must be set.
+type CATrustStore struct {
+	// ConfigMapName is the name of a ConfigMap containing CA certificates in PEM format.
+	ConfigMapName *string
+	// SecretName is the name of a Secret containing CA certificates in PEM format.
+	SecretName *string
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RegistryStatus contains the observed state of the deployed registry caches. It is reported as
+// provider status of the Extension resource.
+type RegistryStatus struct {
+	metav1.TypeMeta
+
+	// Caches reports per-cache statistics observed during the last reconciliation.
+	Caches []RegistryCacheStatus
+	// SuggestedUpstreams are the upstream registries of images running in the shoot that have
+	// no configured cache yet, ordered by the number of containers using them.
+	SuggestedUpstreams []string
+}
+
+// RegistryCacheStatus contains statistics of a single registry cache.
+type RegistryCacheStatus struct {
+	// Upstream is the remote registry host the cache is deployed for.
+	Upstream string
+	// VolumeCapacity is the capacity of the cache volume as observed in the shoot. It is unset
+	// while the volume is not yet provisioned or its metrics are unavailable.
+	VolumeCapacity *resource.Quantity
+	// PulledBytes is the cumulative amount of data served from the cache as observed by the
+	// shoot Prometheus. It is unset while Prometheus or the metric is unavailable.
+	PulledBytes *resource.Quantity
+	// ResolvedConfig is the effective configuration of the cache after defaulting and
+	// merging, so that tooling can introspect implicit behavior without re-implementing the
+	// defaulting. It is a verbatim copy of the cache entry; no field references a Secret.
+	ResolvedConfig *RegistryCache
 }
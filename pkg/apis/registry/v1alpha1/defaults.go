@@ -34,4 +34,8 @@ func SetDefaults_RegistryCache(cache *RegistryCache) {
 	if cache.GarbageCollectionEnabled == nil {
 		cache.GarbageCollectionEnabled = pointer.Bool(true)
 	}
+
+	if cache.UpdateStrategy != nil && cache.UpdateStrategy.Type == nil {
+		cache.UpdateStrategy.Type = pointer.String(UpdateStrategyTypeRollingUpdate)
+	}
 }
@@ -25,6 +25,7 @@ import (
 	unsafe "unsafe"
 
 	registry "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
+	v1 "k8s.io/api/core/v1"
 	resource "k8s.io/apimachinery/pkg/api/resource"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
@@ -37,6 +38,76 @@ func init() {
 // RegisterConversions adds conversion functions to the given scheme.
 // Public to allow building arbitrary schemes.
 func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*CATrustStore)(nil), (*registry.CATrustStore)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_CATrustStore_To_registry_CATrustStore(a.(*CATrustStore), b.(*registry.CATrustStore), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.CATrustStore)(nil), (*CATrustStore)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_CATrustStore_To_v1alpha1_CATrustStore(a.(*registry.CATrustStore), b.(*CATrustStore), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*Debug)(nil), (*registry.Debug)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_Debug_To_registry_Debug(a.(*Debug), b.(*registry.Debug), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.Debug)(nil), (*Debug)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_Debug_To_v1alpha1_Debug(a.(*registry.Debug), b.(*Debug), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*GarbageCollection)(nil), (*registry.GarbageCollection)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_GarbageCollection_To_registry_GarbageCollection(a.(*GarbageCollection), b.(*registry.GarbageCollection), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.GarbageCollection)(nil), (*GarbageCollection)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_GarbageCollection_To_v1alpha1_GarbageCollection(a.(*registry.GarbageCollection), b.(*GarbageCollection), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*LivenessProbe)(nil), (*registry.LivenessProbe)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_LivenessProbe_To_registry_LivenessProbe(a.(*LivenessProbe), b.(*registry.LivenessProbe), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.LivenessProbe)(nil), (*LivenessProbe)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_LivenessProbe_To_v1alpha1_LivenessProbe(a.(*registry.LivenessProbe), b.(*LivenessProbe), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*MaintenanceWindow)(nil), (*registry.MaintenanceWindow)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_MaintenanceWindow_To_registry_MaintenanceWindow(a.(*MaintenanceWindow), b.(*registry.MaintenanceWindow), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.MaintenanceWindow)(nil), (*MaintenanceWindow)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_MaintenanceWindow_To_v1alpha1_MaintenanceWindow(a.(*registry.MaintenanceWindow), b.(*MaintenanceWindow), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*PreferredAffinity)(nil), (*registry.PreferredAffinity)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PreferredAffinity_To_registry_PreferredAffinity(a.(*PreferredAffinity), b.(*registry.PreferredAffinity), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.PreferredAffinity)(nil), (*PreferredAffinity)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_PreferredAffinity_To_v1alpha1_PreferredAffinity(a.(*registry.PreferredAffinity), b.(*PreferredAffinity), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*Proxy)(nil), (*registry.Proxy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_Proxy_To_registry_Proxy(a.(*Proxy), b.(*registry.Proxy), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.Proxy)(nil), (*Proxy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_Proxy_To_v1alpha1_Proxy(a.(*registry.Proxy), b.(*Proxy), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*RegistryCache)(nil), (*registry.RegistryCache)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_RegistryCache_To_registry_RegistryCache(a.(*RegistryCache), b.(*registry.RegistryCache), scope)
 	}); err != nil {
@@ -57,13 +128,189 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*RegistryCacheStatus)(nil), (*registry.RegistryCacheStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_RegistryCacheStatus_To_registry_RegistryCacheStatus(a.(*RegistryCacheStatus), b.(*registry.RegistryCacheStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.RegistryCacheStatus)(nil), (*RegistryCacheStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_RegistryCacheStatus_To_v1alpha1_RegistryCacheStatus(a.(*registry.RegistryCacheStatus), b.(*RegistryCacheStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*RegistryStatus)(nil), (*registry.RegistryStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_RegistryStatus_To_registry_RegistryStatus(a.(*RegistryStatus), b.(*registry.RegistryStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.RegistryStatus)(nil), (*RegistryStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_RegistryStatus_To_v1alpha1_RegistryStatus(a.(*registry.RegistryStatus), b.(*RegistryStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*Service)(nil), (*registry.Service)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_Service_To_registry_Service(a.(*Service), b.(*registry.Service), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.Service)(nil), (*Service)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_Service_To_v1alpha1_Service(a.(*registry.Service), b.(*Service), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*SmokeTest)(nil), (*registry.SmokeTest)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_SmokeTest_To_registry_SmokeTest(a.(*SmokeTest), b.(*registry.SmokeTest), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.SmokeTest)(nil), (*SmokeTest)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_SmokeTest_To_v1alpha1_SmokeTest(a.(*registry.SmokeTest), b.(*SmokeTest), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*StartupProbe)(nil), (*registry.StartupProbe)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_StartupProbe_To_registry_StartupProbe(a.(*StartupProbe), b.(*registry.StartupProbe), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.StartupProbe)(nil), (*StartupProbe)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_StartupProbe_To_v1alpha1_StartupProbe(a.(*registry.StartupProbe), b.(*StartupProbe), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*Storage)(nil), (*registry.Storage)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_Storage_To_registry_Storage(a.(*Storage), b.(*registry.Storage), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.Storage)(nil), (*Storage)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_Storage_To_v1alpha1_Storage(a.(*registry.Storage), b.(*Storage), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*UpdateStrategy)(nil), (*registry.UpdateStrategy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_UpdateStrategy_To_registry_UpdateStrategy(a.(*UpdateStrategy), b.(*registry.UpdateStrategy), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.UpdateStrategy)(nil), (*UpdateStrategy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_UpdateStrategy_To_v1alpha1_UpdateStrategy(a.(*registry.UpdateStrategy), b.(*UpdateStrategy), scope)
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func autoConvert_v1alpha1_CATrustStore_To_registry_CATrustStore(in *CATrustStore, out *registry.CATrustStore, s conversion.Scope) error {
+	out.ConfigMapName = (*string)(unsafe.Pointer(in.ConfigMapName))
+	out.SecretName = (*string)(unsafe.Pointer(in.SecretName))
+	return nil
+}
+
+// Convert_v1alpha1_CATrustStore_To_registry_CATrustStore is an autogenerated conversion function.
+func Convert_v1alpha1_CATrustStore_To_registry_CATrustStore(in *CATrustStore, out *registry.CATrustStore, s conversion.Scope) error {
+	return autoConvert_v1alpha1_CATrustStore_To_registry_CATrustStore(in, out, s)
+}
+
+func autoConvert_registry_CATrustStore_To_v1alpha1_CATrustStore(in *registry.CATrustStore, out *CATrustStore, s conversion.Scope) error {
+	out.ConfigMapName = (*string)(unsafe.Pointer(in.ConfigMapName))
+	out.SecretName = (*string)(unsafe.Pointer(in.SecretName))
+	return nil
+}
+
+// Convert_registry_CATrustStore_To_v1alpha1_CATrustStore is an autogenerated conversion function.
+func Convert_registry_CATrustStore_To_v1alpha1_CATrustStore(in *registry.CATrustStore, out *CATrustStore, s conversion.Scope) error {
+	return autoConvert_registry_CATrustStore_To_v1alpha1_CATrustStore(in, out, s)
+}
+
+func autoConvert_v1alpha1_PreferredAffinity_To_registry_PreferredAffinity(in *PreferredAffinity, out *registry.PreferredAffinity, s conversion.Scope) error {
+	out.LabelSelector = in.LabelSelector
+	out.Weight = (*int32)(unsafe.Pointer(in.Weight))
+	out.TopologyKey = (*string)(unsafe.Pointer(in.TopologyKey))
+	return nil
+}
+
+// Convert_v1alpha1_PreferredAffinity_To_registry_PreferredAffinity is an autogenerated conversion function.
+func Convert_v1alpha1_PreferredAffinity_To_registry_PreferredAffinity(in *PreferredAffinity, out *registry.PreferredAffinity, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PreferredAffinity_To_registry_PreferredAffinity(in, out, s)
+}
+
+func autoConvert_registry_PreferredAffinity_To_v1alpha1_PreferredAffinity(in *registry.PreferredAffinity, out *PreferredAffinity, s conversion.Scope) error {
+	out.LabelSelector = in.LabelSelector
+	out.Weight = (*int32)(unsafe.Pointer(in.Weight))
+	out.TopologyKey = (*string)(unsafe.Pointer(in.TopologyKey))
+	return nil
+}
+
+// Convert_registry_PreferredAffinity_To_v1alpha1_PreferredAffinity is an autogenerated conversion function.
+func Convert_registry_PreferredAffinity_To_v1alpha1_PreferredAffinity(in *registry.PreferredAffinity, out *PreferredAffinity, s conversion.Scope) error {
+	return autoConvert_registry_PreferredAffinity_To_v1alpha1_PreferredAffinity(in, out, s)
+}
+
+func autoConvert_v1alpha1_Proxy_To_registry_Proxy(in *Proxy, out *registry.Proxy, s conversion.Scope) error {
+	out.PinUpstreamIPs = (*bool)(unsafe.Pointer(in.PinUpstreamIPs))
 	return nil
 }
 
+// Convert_v1alpha1_Proxy_To_registry_Proxy is an autogenerated conversion function.
+func Convert_v1alpha1_Proxy_To_registry_Proxy(in *Proxy, out *registry.Proxy, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Proxy_To_registry_Proxy(in, out, s)
+}
+
+func autoConvert_registry_Proxy_To_v1alpha1_Proxy(in *registry.Proxy, out *Proxy, s conversion.Scope) error {
+	out.PinUpstreamIPs = (*bool)(unsafe.Pointer(in.PinUpstreamIPs))
+	return nil
+}
+
+// Convert_registry_Proxy_To_v1alpha1_Proxy is an autogenerated conversion function.
+func Convert_registry_Proxy_To_v1alpha1_Proxy(in *registry.Proxy, out *Proxy, s conversion.Scope) error {
+	return autoConvert_registry_Proxy_To_v1alpha1_Proxy(in, out, s)
+}
+
 func autoConvert_v1alpha1_RegistryCache_To_registry_RegistryCache(in *RegistryCache, out *registry.RegistryCache, s conversion.Scope) error {
 	out.Upstream = in.Upstream
+	out.Insecure = (*bool)(unsafe.Pointer(in.Insecure))
+	out.PathPrefix = (*string)(unsafe.Pointer(in.PathPrefix))
+	out.IngressPathPrefix = (*string)(unsafe.Pointer(in.IngressPathPrefix))
 	out.Size = (*resource.Quantity)(unsafe.Pointer(in.Size))
+	out.VolumeMode = (*string)(unsafe.Pointer(in.VolumeMode))
+	out.Group = (*string)(unsafe.Pointer(in.Group))
+	out.StorageClassName = (*string)(unsafe.Pointer(in.StorageClassName))
 	out.GarbageCollectionEnabled = (*bool)(unsafe.Pointer(in.GarbageCollectionEnabled))
+	out.GarbageCollection = (*registry.GarbageCollection)(unsafe.Pointer(in.GarbageCollection))
+	out.UploadPurging = (*registry.UploadPurging)(unsafe.Pointer(in.UploadPurging))
+	out.CATrustStore = (*registry.CATrustStore)(unsafe.Pointer(in.CATrustStore))
+	out.UpdateStrategy = (*registry.UpdateStrategy)(unsafe.Pointer(in.UpdateStrategy))
+	out.Autoscaling = (*registry.Autoscaling)(unsafe.Pointer(in.Autoscaling))
+	out.Mode = (*string)(unsafe.Pointer(in.Mode))
+	out.HostPort = (*int32)(unsafe.Pointer(in.HostPort))
+	out.ServiceAccountName = (*string)(unsafe.Pointer(in.ServiceAccountName))
+	out.DNSPolicy = (*string)(unsafe.Pointer(in.DNSPolicy))
+	out.DNSConfig = (*v1.PodDNSConfig)(unsafe.Pointer(in.DNSConfig))
+	out.ImagePullPolicy = (*string)(unsafe.Pointer(in.ImagePullPolicy))
+	out.FSGroup = (*int64)(unsafe.Pointer(in.FSGroup))
+	out.FSGroupChangePolicy = (*string)(unsafe.Pointer(in.FSGroupChangePolicy))
+	out.HTTPHost = (*string)(unsafe.Pointer(in.HTTPHost))
+	out.HTTP = (*registry.HTTP)(unsafe.Pointer(in.HTTP))
+	out.MaintenanceWindow = (*registry.MaintenanceWindow)(unsafe.Pointer(in.MaintenanceWindow))
+	out.LivenessProbe = (*registry.LivenessProbe)(unsafe.Pointer(in.LivenessProbe))
+	out.StartupProbe = (*registry.StartupProbe)(unsafe.Pointer(in.StartupProbe))
+	out.ExtraConfig = (*string)(unsafe.Pointer(in.ExtraConfig))
+	out.PreferredAffinity = (*registry.PreferredAffinity)(unsafe.Pointer(in.PreferredAffinity))
+	out.NodeAffinity = (*v1.NodeAffinity)(unsafe.Pointer(in.NodeAffinity))
+	out.FallbackToUpstream = (*bool)(unsafe.Pointer(in.FallbackToUpstream))
+	out.Proxy = (*registry.Proxy)(unsafe.Pointer(in.Proxy))
+	out.Schema1Compatibility = (*bool)(unsafe.Pointer(in.Schema1Compatibility))
+	out.SmokeTest = (*registry.SmokeTest)(unsafe.Pointer(in.SmokeTest))
+	out.Service = (*registry.Service)(unsafe.Pointer(in.Service))
+	out.Debug = (*registry.Debug)(unsafe.Pointer(in.Debug))
+	out.CompressionPolicy = (*string)(unsafe.Pointer(in.CompressionPolicy))
+	out.Storage = (*registry.Storage)(unsafe.Pointer(in.Storage))
+	out.UpstreamReadiness = (*bool)(unsafe.Pointer(in.UpstreamReadiness))
+	out.ResourceProfile = (*string)(unsafe.Pointer(in.ResourceProfile))
+	out.Resources = (*v1.ResourceRequirements)(unsafe.Pointer(in.Resources))
+	out.PodManagementPolicy = (*string)(unsafe.Pointer(in.PodManagementPolicy))
+	out.LongTermMetrics = (*bool)(unsafe.Pointer(in.LongTermMetrics))
 	return nil
 }
 
@@ -74,8 +321,48 @@ func Convert_v1alpha1_RegistryCache_To_registry_RegistryCache(in *RegistryCache,
 
 func autoConvert_registry_RegistryCache_To_v1alpha1_RegistryCache(in *registry.RegistryCache, out *RegistryCache, s conversion.Scope) error {
 	out.Upstream = in.Upstream
+	out.Insecure = (*bool)(unsafe.Pointer(in.Insecure))
+	out.PathPrefix = (*string)(unsafe.Pointer(in.PathPrefix))
+	out.IngressPathPrefix = (*string)(unsafe.Pointer(in.IngressPathPrefix))
 	out.Size = (*resource.Quantity)(unsafe.Pointer(in.Size))
+	out.VolumeMode = (*string)(unsafe.Pointer(in.VolumeMode))
+	out.Group = (*string)(unsafe.Pointer(in.Group))
+	out.StorageClassName = (*string)(unsafe.Pointer(in.StorageClassName))
 	out.GarbageCollectionEnabled = (*bool)(unsafe.Pointer(in.GarbageCollectionEnabled))
+	out.GarbageCollection = (*GarbageCollection)(unsafe.Pointer(in.GarbageCollection))
+	out.UploadPurging = (*UploadPurging)(unsafe.Pointer(in.UploadPurging))
+	out.CATrustStore = (*CATrustStore)(unsafe.Pointer(in.CATrustStore))
+	out.UpdateStrategy = (*UpdateStrategy)(unsafe.Pointer(in.UpdateStrategy))
+	out.Autoscaling = (*Autoscaling)(unsafe.Pointer(in.Autoscaling))
+	out.Mode = (*string)(unsafe.Pointer(in.Mode))
+	out.HostPort = (*int32)(unsafe.Pointer(in.HostPort))
+	out.ServiceAccountName = (*string)(unsafe.Pointer(in.ServiceAccountName))
+	out.DNSPolicy = (*string)(unsafe.Pointer(in.DNSPolicy))
+	out.DNSConfig = (*v1.PodDNSConfig)(unsafe.Pointer(in.DNSConfig))
+	out.ImagePullPolicy = (*string)(unsafe.Pointer(in.ImagePullPolicy))
+	out.FSGroup = (*int64)(unsafe.Pointer(in.FSGroup))
+	out.FSGroupChangePolicy = (*string)(unsafe.Pointer(in.FSGroupChangePolicy))
+	out.HTTPHost = (*string)(unsafe.Pointer(in.HTTPHost))
+	out.HTTP = (*HTTP)(unsafe.Pointer(in.HTTP))
+	out.MaintenanceWindow = (*MaintenanceWindow)(unsafe.Pointer(in.MaintenanceWindow))
+	out.LivenessProbe = (*LivenessProbe)(unsafe.Pointer(in.LivenessProbe))
+	out.StartupProbe = (*StartupProbe)(unsafe.Pointer(in.StartupProbe))
+	out.ExtraConfig = (*string)(unsafe.Pointer(in.ExtraConfig))
+	out.PreferredAffinity = (*PreferredAffinity)(unsafe.Pointer(in.PreferredAffinity))
+	out.NodeAffinity = (*v1.NodeAffinity)(unsafe.Pointer(in.NodeAffinity))
+	out.FallbackToUpstream = (*bool)(unsafe.Pointer(in.FallbackToUpstream))
+	out.Proxy = (*Proxy)(unsafe.Pointer(in.Proxy))
+	out.Schema1Compatibility = (*bool)(unsafe.Pointer(in.Schema1Compatibility))
+	out.SmokeTest = (*SmokeTest)(unsafe.Pointer(in.SmokeTest))
+	out.Service = (*Service)(unsafe.Pointer(in.Service))
+	out.Debug = (*Debug)(unsafe.Pointer(in.Debug))
+	out.CompressionPolicy = (*string)(unsafe.Pointer(in.CompressionPolicy))
+	out.Storage = (*Storage)(unsafe.Pointer(in.Storage))
+	out.UpstreamReadiness = (*bool)(unsafe.Pointer(in.UpstreamReadiness))
+	out.ResourceProfile = (*string)(unsafe.Pointer(in.ResourceProfile))
+	out.Resources = (*v1.ResourceRequirements)(unsafe.Pointer(in.Resources))
+	out.PodManagementPolicy = (*string)(unsafe.Pointer(in.PodManagementPolicy))
+	out.LongTermMetrics = (*bool)(unsafe.Pointer(in.LongTermMetrics))
 	return nil
 }
 
@@ -86,6 +373,16 @@ func Convert_registry_RegistryCache_To_v1alpha1_RegistryCache(in *registry.Regis
 
 func autoConvert_v1alpha1_RegistryConfig_To_registry_RegistryConfig(in *RegistryConfig, out *registry.RegistryConfig, s conversion.Scope) error {
 	out.Caches = *(*[]registry.RegistryCache)(unsafe.Pointer(&in.Caches))
+	out.SuggestCaches = (*bool)(unsafe.Pointer(in.SuggestCaches))
+	out.AutoCreateCaches = (*bool)(unsafe.Pointer(in.AutoCreateCaches))
+	out.CacheSystemImages = (*bool)(unsafe.Pointer(in.CacheSystemImages))
+	out.Namespace = (*string)(unsafe.Pointer(in.Namespace))
+	out.Quota = (*resource.Quantity)(unsafe.Pointer(in.Quota))
+	out.MirrorConfigFormat = (*string)(unsafe.Pointer(in.MirrorConfigFormat))
+	out.ProbeUpstreams = (*bool)(unsafe.Pointer(in.ProbeUpstreams))
+	out.StorageTiering = (*registry.StorageTiering)(unsafe.Pointer(in.StorageTiering))
+	out.InShootMonitoring = (*registry.InShootMonitoring)(unsafe.Pointer(in.InShootMonitoring))
+	out.Ingress = (*registry.Ingress)(unsafe.Pointer(in.Ingress))
 	return nil
 }
 
@@ -96,6 +393,16 @@ func Convert_v1alpha1_RegistryConfig_To_registry_RegistryConfig(in *RegistryConf
 
 func autoConvert_registry_RegistryConfig_To_v1alpha1_RegistryConfig(in *registry.RegistryConfig, out *RegistryConfig, s conversion.Scope) error {
 	out.Caches = *(*[]RegistryCache)(unsafe.Pointer(&in.Caches))
+	out.SuggestCaches = (*bool)(unsafe.Pointer(in.SuggestCaches))
+	out.AutoCreateCaches = (*bool)(unsafe.Pointer(in.AutoCreateCaches))
+	out.CacheSystemImages = (*bool)(unsafe.Pointer(in.CacheSystemImages))
+	out.Namespace = (*string)(unsafe.Pointer(in.Namespace))
+	out.Quota = (*resource.Quantity)(unsafe.Pointer(in.Quota))
+	out.MirrorConfigFormat = (*string)(unsafe.Pointer(in.MirrorConfigFormat))
+	out.ProbeUpstreams = (*bool)(unsafe.Pointer(in.ProbeUpstreams))
+	out.StorageTiering = (*StorageTiering)(unsafe.Pointer(in.StorageTiering))
+	out.InShootMonitoring = (*InShootMonitoring)(unsafe.Pointer(in.InShootMonitoring))
+	out.Ingress = (*Ingress)(unsafe.Pointer(in.Ingress))
 	return nil
 }
 
@@ -103,3 +410,255 @@ func autoConvert_registry_RegistryConfig_To_v1alpha1_RegistryConfig(in *registry
 func Convert_registry_RegistryConfig_To_v1alpha1_RegistryConfig(in *registry.RegistryConfig, out *RegistryConfig, s conversion.Scope) error {
 	return autoConvert_registry_RegistryConfig_To_v1alpha1_RegistryConfig(in, out, s)
 }
+
+func autoConvert_v1alpha1_Debug_To_registry_Debug(in *Debug, out *registry.Debug, s conversion.Scope) error {
+	out.Port = (*int32)(unsafe.Pointer(in.Port))
+	out.PprofEnabled = (*bool)(unsafe.Pointer(in.PprofEnabled))
+	return nil
+}
+
+// Convert_v1alpha1_Debug_To_registry_Debug is an autogenerated conversion function.
+func Convert_v1alpha1_Debug_To_registry_Debug(in *Debug, out *registry.Debug, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Debug_To_registry_Debug(in, out, s)
+}
+
+func autoConvert_registry_Debug_To_v1alpha1_Debug(in *registry.Debug, out *Debug, s conversion.Scope) error {
+	out.Port = (*int32)(unsafe.Pointer(in.Port))
+	out.PprofEnabled = (*bool)(unsafe.Pointer(in.PprofEnabled))
+	return nil
+}
+
+// Convert_registry_Debug_To_v1alpha1_Debug is an autogenerated conversion function.
+func Convert_registry_Debug_To_v1alpha1_Debug(in *registry.Debug, out *Debug, s conversion.Scope) error {
+	return autoConvert_registry_Debug_To_v1alpha1_Debug(in, out, s)
+}
+
+func autoConvert_v1alpha1_GarbageCollection_To_registry_GarbageCollection(in *GarbageCollection, out *registry.GarbageCollection, s conversion.Scope) error {
+	out.DeleteUntagged = (*bool)(unsafe.Pointer(in.DeleteUntagged))
+	out.ReadOnly = (*bool)(unsafe.Pointer(in.ReadOnly))
+	out.MinFreeBytes = (*resource.Quantity)(unsafe.Pointer(in.MinFreeBytes))
+	return nil
+}
+
+// Convert_v1alpha1_GarbageCollection_To_registry_GarbageCollection is an autogenerated conversion function.
+func Convert_v1alpha1_GarbageCollection_To_registry_GarbageCollection(in *GarbageCollection, out *registry.GarbageCollection, s conversion.Scope) error {
+	return autoConvert_v1alpha1_GarbageCollection_To_registry_GarbageCollection(in, out, s)
+}
+
+func autoConvert_registry_GarbageCollection_To_v1alpha1_GarbageCollection(in *registry.GarbageCollection, out *GarbageCollection, s conversion.Scope) error {
+	out.DeleteUntagged = (*bool)(unsafe.Pointer(in.DeleteUntagged))
+	out.ReadOnly = (*bool)(unsafe.Pointer(in.ReadOnly))
+	out.MinFreeBytes = (*resource.Quantity)(unsafe.Pointer(in.MinFreeBytes))
+	return nil
+}
+
+// Convert_registry_GarbageCollection_To_v1alpha1_GarbageCollection is an autogenerated conversion function.
+func Convert_registry_GarbageCollection_To_v1alpha1_GarbageCollection(in *registry.GarbageCollection, out *GarbageCollection, s conversion.Scope) error {
+	return autoConvert_registry_GarbageCollection_To_v1alpha1_GarbageCollection(in, out, s)
+}
+
+func autoConvert_v1alpha1_LivenessProbe_To_registry_LivenessProbe(in *LivenessProbe, out *registry.LivenessProbe, s conversion.Scope) error {
+	out.Path = (*string)(unsafe.Pointer(in.Path))
+	out.InitialDelaySeconds = (*int32)(unsafe.Pointer(in.InitialDelaySeconds))
+	out.PeriodSeconds = (*int32)(unsafe.Pointer(in.PeriodSeconds))
+	out.TimeoutSeconds = (*int32)(unsafe.Pointer(in.TimeoutSeconds))
+	out.FailureThreshold = (*int32)(unsafe.Pointer(in.FailureThreshold))
+	return nil
+}
+
+// Convert_v1alpha1_LivenessProbe_To_registry_LivenessProbe is an autogenerated conversion function.
+func Convert_v1alpha1_LivenessProbe_To_registry_LivenessProbe(in *LivenessProbe, out *registry.LivenessProbe, s conversion.Scope) error {
+	return autoConvert_v1alpha1_LivenessProbe_To_registry_LivenessProbe(in, out, s)
+}
+
+func autoConvert_registry_LivenessProbe_To_v1alpha1_LivenessProbe(in *registry.LivenessProbe, out *LivenessProbe, s conversion.Scope) error {
+	out.Path = (*string)(unsafe.Pointer(in.Path))
+	out.InitialDelaySeconds = (*int32)(unsafe.Pointer(in.InitialDelaySeconds))
+	out.PeriodSeconds = (*int32)(unsafe.Pointer(in.PeriodSeconds))
+	out.TimeoutSeconds = (*int32)(unsafe.Pointer(in.TimeoutSeconds))
+	out.FailureThreshold = (*int32)(unsafe.Pointer(in.FailureThreshold))
+	return nil
+}
+
+// Convert_registry_LivenessProbe_To_v1alpha1_LivenessProbe is an autogenerated conversion function.
+func Convert_registry_LivenessProbe_To_v1alpha1_LivenessProbe(in *registry.LivenessProbe, out *LivenessProbe, s conversion.Scope) error {
+	return autoConvert_registry_LivenessProbe_To_v1alpha1_LivenessProbe(in, out, s)
+}
+
+func autoConvert_v1alpha1_MaintenanceWindow_To_registry_MaintenanceWindow(in *MaintenanceWindow, out *registry.MaintenanceWindow, s conversion.Scope) error {
+	out.Begin = in.Begin
+	out.End = in.End
+	return nil
+}
+
+// Convert_v1alpha1_MaintenanceWindow_To_registry_MaintenanceWindow is an autogenerated conversion function.
+func Convert_v1alpha1_MaintenanceWindow_To_registry_MaintenanceWindow(in *MaintenanceWindow, out *registry.MaintenanceWindow, s conversion.Scope) error {
+	return autoConvert_v1alpha1_MaintenanceWindow_To_registry_MaintenanceWindow(in, out, s)
+}
+
+func autoConvert_registry_MaintenanceWindow_To_v1alpha1_MaintenanceWindow(in *registry.MaintenanceWindow, out *MaintenanceWindow, s conversion.Scope) error {
+	out.Begin = in.Begin
+	out.End = in.End
+	return nil
+}
+
+// Convert_registry_MaintenanceWindow_To_v1alpha1_MaintenanceWindow is an autogenerated conversion function.
+func Convert_registry_MaintenanceWindow_To_v1alpha1_MaintenanceWindow(in *registry.MaintenanceWindow, out *MaintenanceWindow, s conversion.Scope) error {
+	return autoConvert_registry_MaintenanceWindow_To_v1alpha1_MaintenanceWindow(in, out, s)
+}
+
+func autoConvert_v1alpha1_RegistryCacheStatus_To_registry_RegistryCacheStatus(in *RegistryCacheStatus, out *registry.RegistryCacheStatus, s conversion.Scope) error {
+	out.Upstream = in.Upstream
+	out.VolumeCapacity = (*resource.Quantity)(unsafe.Pointer(in.VolumeCapacity))
+	out.PulledBytes = (*resource.Quantity)(unsafe.Pointer(in.PulledBytes))
+	out.ResolvedConfig = (*registry.RegistryCache)(unsafe.Pointer(in.ResolvedConfig))
+	return nil
+}
+
+// Convert_v1alpha1_RegistryCacheStatus_To_registry_RegistryCacheStatus is an autogenerated conversion function.
+func Convert_v1alpha1_RegistryCacheStatus_To_registry_RegistryCacheStatus(in *RegistryCacheStatus, out *registry.RegistryCacheStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_RegistryCacheStatus_To_registry_RegistryCacheStatus(in, out, s)
+}
+
+func autoConvert_registry_RegistryCacheStatus_To_v1alpha1_RegistryCacheStatus(in *registry.RegistryCacheStatus, out *RegistryCacheStatus, s conversion.Scope) error {
+	out.Upstream = in.Upstream
+	out.VolumeCapacity = (*resource.Quantity)(unsafe.Pointer(in.VolumeCapacity))
+	out.PulledBytes = (*resource.Quantity)(unsafe.Pointer(in.PulledBytes))
+	out.ResolvedConfig = (*RegistryCache)(unsafe.Pointer(in.ResolvedConfig))
+	return nil
+}
+
+// Convert_registry_RegistryCacheStatus_To_v1alpha1_RegistryCacheStatus is an autogenerated conversion function.
+func Convert_registry_RegistryCacheStatus_To_v1alpha1_RegistryCacheStatus(in *registry.RegistryCacheStatus, out *RegistryCacheStatus, s conversion.Scope) error {
+	return autoConvert_registry_RegistryCacheStatus_To_v1alpha1_RegistryCacheStatus(in, out, s)
+}
+
+func autoConvert_v1alpha1_RegistryStatus_To_registry_RegistryStatus(in *RegistryStatus, out *registry.RegistryStatus, s conversion.Scope) error {
+	out.Caches = *(*[]registry.RegistryCacheStatus)(unsafe.Pointer(&in.Caches))
+	out.SuggestedUpstreams = *(*[]string)(unsafe.Pointer(&in.SuggestedUpstreams))
+	return nil
+}
+
+// Convert_v1alpha1_RegistryStatus_To_registry_RegistryStatus is an autogenerated conversion function.
+func Convert_v1alpha1_RegistryStatus_To_registry_RegistryStatus(in *RegistryStatus, out *registry.RegistryStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_RegistryStatus_To_registry_RegistryStatus(in, out, s)
+}
+
+func autoConvert_registry_RegistryStatus_To_v1alpha1_RegistryStatus(in *registry.RegistryStatus, out *RegistryStatus, s conversion.Scope) error {
+	out.Caches = *(*[]RegistryCacheStatus)(unsafe.Pointer(&in.Caches))
+	out.SuggestedUpstreams = *(*[]string)(unsafe.Pointer(&in.SuggestedUpstreams))
+	return nil
+}
+
+// Convert_registry_RegistryStatus_To_v1alpha1_RegistryStatus is an autogenerated conversion function.
+func Convert_registry_RegistryStatus_To_v1alpha1_RegistryStatus(in *registry.RegistryStatus, out *RegistryStatus, s conversion.Scope) error {
+	return autoConvert_registry_RegistryStatus_To_v1alpha1_RegistryStatus(in, out, s)
+}
+
+func autoConvert_v1alpha1_Service_To_registry_Service(in *Service, out *registry.Service, s conversion.Scope) error {
+	out.Type = (*string)(unsafe.Pointer(in.Type))
+	out.SessionAffinity = (*string)(unsafe.Pointer(in.SessionAffinity))
+	out.SessionAffinityTimeoutSeconds = (*int32)(unsafe.Pointer(in.SessionAffinityTimeoutSeconds))
+	return nil
+}
+
+// Convert_v1alpha1_Service_To_registry_Service is an autogenerated conversion function.
+func Convert_v1alpha1_Service_To_registry_Service(in *Service, out *registry.Service, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Service_To_registry_Service(in, out, s)
+}
+
+func autoConvert_registry_Service_To_v1alpha1_Service(in *registry.Service, out *Service, s conversion.Scope) error {
+	out.Type = (*string)(unsafe.Pointer(in.Type))
+	out.SessionAffinity = (*string)(unsafe.Pointer(in.SessionAffinity))
+	out.SessionAffinityTimeoutSeconds = (*int32)(unsafe.Pointer(in.SessionAffinityTimeoutSeconds))
+	return nil
+}
+
+// Convert_registry_Service_To_v1alpha1_Service is an autogenerated conversion function.
+func Convert_registry_Service_To_v1alpha1_Service(in *registry.Service, out *Service, s conversion.Scope) error {
+	return autoConvert_registry_Service_To_v1alpha1_Service(in, out, s)
+}
+
+func autoConvert_v1alpha1_SmokeTest_To_registry_SmokeTest(in *SmokeTest, out *registry.SmokeTest, s conversion.Scope) error {
+	out.Image = in.Image
+	return nil
+}
+
+// Convert_v1alpha1_SmokeTest_To_registry_SmokeTest is an autogenerated conversion function.
+func Convert_v1alpha1_SmokeTest_To_registry_SmokeTest(in *SmokeTest, out *registry.SmokeTest, s conversion.Scope) error {
+	return autoConvert_v1alpha1_SmokeTest_To_registry_SmokeTest(in, out, s)
+}
+
+func autoConvert_registry_SmokeTest_To_v1alpha1_SmokeTest(in *registry.SmokeTest, out *SmokeTest, s conversion.Scope) error {
+	out.Image = in.Image
+	return nil
+}
+
+// Convert_registry_SmokeTest_To_v1alpha1_SmokeTest is an autogenerated conversion function.
+func Convert_registry_SmokeTest_To_v1alpha1_SmokeTest(in *registry.SmokeTest, out *SmokeTest, s conversion.Scope) error {
+	return autoConvert_registry_SmokeTest_To_v1alpha1_SmokeTest(in, out, s)
+}
+
+func autoConvert_v1alpha1_StartupProbe_To_registry_StartupProbe(in *StartupProbe, out *registry.StartupProbe, s conversion.Scope) error {
+	out.PeriodSeconds = (*int32)(unsafe.Pointer(in.PeriodSeconds))
+	out.FailureThreshold = (*int32)(unsafe.Pointer(in.FailureThreshold))
+	return nil
+}
+
+// Convert_v1alpha1_StartupProbe_To_registry_StartupProbe is an autogenerated conversion function.
+func Convert_v1alpha1_StartupProbe_To_registry_StartupProbe(in *StartupProbe, out *registry.StartupProbe, s conversion.Scope) error {
+	return autoConvert_v1alpha1_StartupProbe_To_registry_StartupProbe(in, out, s)
+}
+
+func autoConvert_registry_StartupProbe_To_v1alpha1_StartupProbe(in *registry.StartupProbe, out *StartupProbe, s conversion.Scope) error {
+	out.PeriodSeconds = (*int32)(unsafe.Pointer(in.PeriodSeconds))
+	out.FailureThreshold = (*int32)(unsafe.Pointer(in.FailureThreshold))
+	return nil
+}
+
+// Convert_registry_StartupProbe_To_v1alpha1_StartupProbe is an autogenerated conversion function.
+func Convert_registry_StartupProbe_To_v1alpha1_StartupProbe(in *registry.StartupProbe, out *StartupProbe, s conversion.Scope) error {
+	return autoConvert_registry_StartupProbe_To_v1alpha1_StartupProbe(in, out, s)
+}
+
+func autoConvert_v1alpha1_Storage_To_registry_Storage(in *Storage, out *registry.Storage, s conversion.Scope) error {
+	out.DriverParameters = *(*map[string]string)(unsafe.Pointer(&in.DriverParameters))
+	return nil
+}
+
+// Convert_v1alpha1_Storage_To_registry_Storage is an autogenerated conversion function.
+func Convert_v1alpha1_Storage_To_registry_Storage(in *Storage, out *registry.Storage, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Storage_To_registry_Storage(in, out, s)
+}
+
+func autoConvert_registry_Storage_To_v1alpha1_Storage(in *registry.Storage, out *Storage, s conversion.Scope) error {
+	out.DriverParameters = *(*map[string]string)(unsafe.Pointer(&in.DriverParameters))
+	return nil
+}
+
+// Convert_registry_Storage_To_v1alpha1_Storage is an autogenerated conversion function.
+func Convert_registry_Storage_To_v1alpha1_Storage(in *registry.Storage, out *Storage, s conversion.Scope) error {
+	return autoConvert_registry_Storage_To_v1alpha1_Storage(in, out, s)
+}
+
+func autoConvert_v1alpha1_UpdateStrategy_To_registry_UpdateStrategy(in *UpdateStrategy, out *registry.UpdateStrategy, s conversion.Scope) error {
+	out.Type = (*string)(unsafe.Pointer(in.Type))
+	out.Partition = (*int32)(unsafe.Pointer(in.Partition))
+	return nil
+}
+
+// Convert_v1alpha1_UpdateStrategy_To_registry_UpdateStrategy is an autogenerated conversion function.
+func Convert_v1alpha1_UpdateStrategy_To_registry_UpdateStrategy(in *UpdateStrategy, out *registry.UpdateStrategy, s conversion.Scope) error {
+	return autoConvert_v1alpha1_UpdateStrategy_To_registry_UpdateStrategy(in, out, s)
+}
+
+func autoConvert_registry_UpdateStrategy_To_v1alpha1_UpdateStrategy(in *registry.UpdateStrategy, out *UpdateStrategy, s conversion.Scope) error {
+	out.Type = (*string)(unsafe.Pointer(in.Type))
+	out.Partition = (*int32)(unsafe.Pointer(in.Partition))
+	return nil
+}
+
+// Convert_registry_UpdateStrategy_To_v1alpha1_UpdateStrategy is an autogenerated conversion function.
+func Convert_registry_UpdateStrategy_To_v1alpha1_UpdateStrategy(in *registry.UpdateStrategy, out *UpdateStrategy, s conversion.Scope) error {
+	return autoConvert_registry_UpdateStrategy_To_v1alpha1_UpdateStrategy(in, out, s)
+}
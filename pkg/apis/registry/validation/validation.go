@@ -15,19 +15,109 @@
 package validation
 
 import (
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
 	"strings"
 
+	"github.com/gardener/gardener/pkg/utils/timewindow"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
 
 	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
 )
 
+// pathPrefixRegexp matches one or more non-empty path segments, each starting with a "/".
+var pathPrefixRegexp = regexp.MustCompile(`^(/[A-Za-z0-9._-]+)+$`)
+
 // ValidateRegistryConfig validates the passed configuration instance.
 func ValidateRegistryConfig(config *registry.RegistryConfig, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
+	seenUpstreams := map[string]struct{}{}
 	for i, cache := range config.Caches {
 		allErrs = append(allErrs, validateRegistryCache(cache, fldPath.Child("caches").Index(i))...)
+
+		// two caches for the very same upstream would route non-deterministically
+		if _, ok := seenUpstreams[cache.Upstream]; ok {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("caches").Index(i).Child("upstream"), cache.Upstream))
+		}
+		seenUpstreams[cache.Upstream] = struct{}{}
+	}
+
+	if config.AutoCreateCaches != nil && *config.AutoCreateCaches && (config.SuggestCaches == nil || !*config.SuggestCaches) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("autoCreateCaches"), "autoCreateCaches requires suggestCaches to be enabled"))
+	}
+
+	if namespace := config.Namespace; namespace != nil {
+		for _, msg := range apivalidation.ValidateNamespaceName(*namespace, false) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespace"), *namespace, msg))
+		}
+		if *namespace == metav1.NamespaceSystem || *namespace == metav1.NamespaceDefault {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("namespace"), "the caches must not be deployed into a system namespace"))
+		}
+	}
+	if quota := config.Quota; quota != nil && quota.Sign() != 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("quota"), quota, "quota must be a quantity greater than zero"))
+	}
+
+	if format := config.MirrorConfigFormat; format != nil && *format != registry.MirrorConfigFormatInline && *format != registry.MirrorConfigFormatHosts {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("mirrorConfigFormat"), *format, []string{registry.MirrorConfigFormatInline, registry.MirrorConfigFormatHosts}))
+	}
+
+	if tiering := config.StorageTiering; tiering != nil {
+		if tiering.HotStorageClassName == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("storageTiering", "hotStorageClassName"), "hotStorageClassName is required"))
+		}
+		if tiering.ColdStorageClassName == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("storageTiering", "coldStorageClassName"), "coldStorageClassName is required"))
+		} else if tiering.ColdStorageClassName == tiering.HotStorageClassName {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("storageTiering", "coldStorageClassName"), tiering.ColdStorageClassName, "the hot and cold tiers must use different storage classes"))
+		}
+		if tiering.HotBytesThreshold != nil && tiering.HotBytesThreshold.Sign() != 1 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("storageTiering", "hotBytesThreshold"), tiering.HotBytesThreshold, "hotBytesThreshold must be a quantity greater than zero"))
+		}
+		if tiering.MigrationWindow != nil {
+			if _, err := timewindow.ParseMaintenanceTimeWindow(tiering.MigrationWindow.Begin, tiering.MigrationWindow.End); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("storageTiering", "migrationWindow"), tiering.MigrationWindow, fmt.Sprintf("migrationWindow is invalid: %v; desired format: HHMMSS+ZZZZ", err)))
+			}
+		}
+	}
+
+	if monitoring := config.InShootMonitoring; monitoring != nil {
+		allErrs = append(allErrs, metav1validation.ValidateLabels(monitoring.Labels, fldPath.Child("inShootMonitoring", "labels"))...)
+	}
+
+	if ingress := config.Ingress; ingress != nil {
+		if ingress.Host == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("ingress", "host"), "host is required"))
+		} else {
+			for _, msg := range utilvalidation.IsDNS1123Subdomain(ingress.Host) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("ingress", "host"), ingress.Host, msg))
+			}
+		}
+
+		// two caches served under the same prefix would route non-deterministically; the
+		// defaulted prefixes are unique because the upstreams are
+		seenPrefixes := map[string]struct{}{}
+		for i, cache := range config.Caches {
+			prefix := "/" + cache.Upstream
+			if cache.IngressPathPrefix != nil {
+				prefix = *cache.IngressPathPrefix
+			}
+			if _, ok := seenPrefixes[prefix]; ok {
+				allErrs = append(allErrs, field.Duplicate(fldPath.Child("caches").Index(i).Child("ingressPathPrefix"), prefix))
+			}
+			seenPrefixes[prefix] = struct{}{}
+		}
 	}
 
 	return allErrs
@@ -37,9 +127,441 @@ func validateRegistryCache(cache registry.RegistryCache, fldPath *field.Path) fi
 	var allErrs field.ErrorList
 
 	allErrs = append(allErrs, validateUpstream(fldPath.Child("upstream"), cache.Upstream)...)
+
+	if cache.Insecure != nil && *cache.Insecure && cache.Upstream == "docker.io" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("insecure"), "docker.io is always reachable via HTTPS and cannot be used insecurely"))
+	}
+	if cache.PathPrefix != nil && !pathPrefixRegexp.MatchString(*cache.PathPrefix) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("pathPrefix"), *cache.PathPrefix, "pathPrefix must start with a \"/\" and must not end with one; desired format: /path[/path]"))
+	}
+	if cache.IngressPathPrefix != nil && !pathPrefixRegexp.MatchString(*cache.IngressPathPrefix) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("ingressPathPrefix"), *cache.IngressPathPrefix, "ingressPathPrefix must start with a \"/\" and must not end with one; desired format: /path[/path]"))
+	}
 	if size := cache.Size; size != nil && size.Sign() != 1 {
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("size"), size, "size must be a quantity greater than zero"))
 	}
+	if mode := cache.VolumeMode; mode != nil {
+		if *mode != string(corev1.PersistentVolumeFilesystem) && *mode != string(corev1.PersistentVolumeBlock) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("volumeMode"), *mode, []string{string(corev1.PersistentVolumeFilesystem), string(corev1.PersistentVolumeBlock)}))
+		} else if *mode == string(corev1.PersistentVolumeBlock) {
+			// the registry stores its content with the filesystem storage driver; a raw block
+			// device would first have to be formatted by a privileged init step
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("volumeMode"), "the \"Block\" volume mode is not supported; the registry requires a filesystem on its cache volume"))
+		}
+	}
+	if gc := cache.GarbageCollection; gc != nil {
+		if gc.DeleteUntagged != nil && *gc.DeleteUntagged && gc.ReadOnly != nil && *gc.ReadOnly {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("garbageCollection", "deleteUntagged"), "deleteUntagged cannot be combined with readOnly"))
+		}
+		if gc.MinFreeBytes != nil && gc.MinFreeBytes.Sign() != 1 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("garbageCollection", "minFreeBytes"), gc.MinFreeBytes, "minFreeBytes must be a quantity greater than zero"))
+		}
+	}
+	if cache.CATrustStore != nil {
+		allErrs = append(allErrs, validateCATrustStore(fldPath.Child("caTrustStore"), cache.CATrustStore)...)
+	}
+	if cache.UpdateStrategy != nil {
+		allErrs = append(allErrs, validateUpdateStrategy(fldPath.Child("updateStrategy"), cache.UpdateStrategy)...)
+	}
+	allErrs = append(allErrs, validateMode(fldPath, cache)...)
+	allErrs = append(allErrs, validateDNS(fldPath, cache.DNSPolicy, cache.DNSConfig)...)
+
+	if cache.FSGroup != nil && (*cache.FSGroup < 0 || *cache.FSGroup > math.MaxInt32) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("fsGroup"), *cache.FSGroup, fmt.Sprintf("fsGroup must be in the range 0 to %d", math.MaxInt32)))
+	}
+	if policy := cache.ImagePullPolicy; policy != nil && *policy != string(corev1.PullAlways) && *policy != string(corev1.PullIfNotPresent) && *policy != string(corev1.PullNever) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("imagePullPolicy"), *policy, []string{string(corev1.PullAlways), string(corev1.PullIfNotPresent), string(corev1.PullNever)}))
+	}
+	if policy := cache.FSGroupChangePolicy; policy != nil && *policy != string(corev1.FSGroupChangeOnRootMismatch) && *policy != string(corev1.FSGroupChangeAlways) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("fsGroupChangePolicy"), *policy, []string{string(corev1.FSGroupChangeOnRootMismatch), string(corev1.FSGroupChangeAlways)}))
+	}
+
+	if cache.LivenessProbe != nil {
+		allErrs = append(allErrs, validateLivenessProbe(fldPath.Child("livenessProbe"), cache.LivenessProbe)...)
+	}
+
+	if purging := cache.UploadPurging; purging != nil {
+		if purging.Age != nil && purging.Age.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("uploadPurging", "age"), purging.Age.Duration.String(), "age must be a duration greater than zero"))
+		}
+		if purging.Interval != nil && purging.Interval.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("uploadPurging", "interval"), purging.Interval.Duration.String(), "interval must be a duration greater than zero"))
+		}
+	}
+
+	if cache.StartupProbe != nil {
+		allErrs = append(allErrs, validateStartupProbe(fldPath.Child("startupProbe"), cache.StartupProbe)...)
+	}
+
+	if cache.MaintenanceWindow != nil {
+		if _, err := timewindow.ParseMaintenanceTimeWindow(cache.MaintenanceWindow.Begin, cache.MaintenanceWindow.End); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("maintenanceWindow"), cache.MaintenanceWindow, fmt.Sprintf("maintenanceWindow is invalid: %v; desired format: HHMMSS+ZZZZ", err)))
+		}
+	}
+
+	if cache.ExtraConfig != nil {
+		allErrs = append(allErrs, validateExtraConfig(fldPath.Child("extraConfig"), *cache.ExtraConfig)...)
+	}
+
+	if cache.PreferredAffinity != nil {
+		allErrs = append(allErrs, validatePreferredAffinity(fldPath.Child("preferredAffinity"), cache.PreferredAffinity)...)
+	}
+
+	if cache.Autoscaling != nil {
+		autoscaling := cache.Autoscaling
+		autoscalingPath := fldPath.Child("autoscaling")
+		if cache.Mode != nil && *cache.Mode == registry.ModeDaemonSet {
+			allErrs = append(allErrs, field.Forbidden(autoscalingPath, "autoscaling is not supported in DaemonSet mode"))
+		}
+		if autoscaling.MaxReplicas < 1 {
+			allErrs = append(allErrs, field.Invalid(autoscalingPath.Child("maxReplicas"), autoscaling.MaxReplicas, "maxReplicas must be at least 1"))
+		}
+		if autoscaling.MinReplicas != nil && *autoscaling.MinReplicas < 1 {
+			allErrs = append(allErrs, field.Invalid(autoscalingPath.Child("minReplicas"), *autoscaling.MinReplicas, "minReplicas must be at least 1"))
+		}
+		if autoscaling.MinReplicas != nil && *autoscaling.MinReplicas > autoscaling.MaxReplicas {
+			allErrs = append(allErrs, field.Invalid(autoscalingPath.Child("minReplicas"), *autoscaling.MinReplicas, "minReplicas must not exceed maxReplicas"))
+		}
+		if autoscaling.TargetCPUUtilization != nil && (*autoscaling.TargetCPUUtilization < 1 || *autoscaling.TargetCPUUtilization > 100) {
+			allErrs = append(allErrs, field.Invalid(autoscalingPath.Child("targetCPUUtilization"), *autoscaling.TargetCPUUtilization, "targetCPUUtilization must be in the range [1, 100]"))
+		}
+		if autoscaling.TargetMemoryUtilization != nil && (*autoscaling.TargetMemoryUtilization < 1 || *autoscaling.TargetMemoryUtilization > 100) {
+			allErrs = append(allErrs, field.Invalid(autoscalingPath.Child("targetMemoryUtilization"), *autoscaling.TargetMemoryUtilization, "targetMemoryUtilization must be in the range [1, 100]"))
+		}
+		if autoscaling.TargetCPUUtilization == nil && autoscaling.TargetMemoryUtilization == nil {
+			allErrs = append(allErrs, field.Required(autoscalingPath, "at least one scaling target must be configured"))
+		}
+	}
+
+	if cache.Group != nil {
+		for _, msg := range utilvalidation.IsDNS1123Label(*cache.Group) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("group"), *cache.Group, msg))
+		}
+	}
+
+	if cache.SmokeTest != nil && cache.SmokeTest.Image == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("smokeTest", "image"), "image must be provided"))
+	}
+
+	if cache.Service != nil {
+		allErrs = append(allErrs, validateService(fldPath.Child("service"), cache.Service)...)
+	}
+
+	if policy := cache.CompressionPolicy; policy != nil && *policy != registry.CompressionPolicyNone && *policy != registry.CompressionPolicyReportOnly {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("compressionPolicy"), *policy, []string{registry.CompressionPolicyNone, registry.CompressionPolicyReportOnly}))
+	}
+
+	if profile := cache.ResourceProfile; profile != nil && *profile != registry.ResourceProfileSmall && *profile != registry.ResourceProfileMedium && *profile != registry.ResourceProfileLarge {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("resourceProfile"), *profile, []string{registry.ResourceProfileSmall, registry.ResourceProfileMedium, registry.ResourceProfileLarge}))
+	}
+	if policy := cache.PodManagementPolicy; policy != nil && *policy != string(appsv1.OrderedReadyPodManagement) && *policy != string(appsv1.ParallelPodManagement) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("podManagementPolicy"), *policy, []string{string(appsv1.OrderedReadyPodManagement), string(appsv1.ParallelPodManagement)}))
+	}
+
+	if cache.ResourceProfile != nil && cache.Resources != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("resources"), "resources cannot be combined with a resourceProfile; set explicit resources instead of a profile"))
+	}
+
+	if cache.Storage != nil {
+		allErrs = append(allErrs, validateStorage(fldPath.Child("storage"), cache.Storage)...)
+	}
+
+	// pprof on the debug endpoint is deliberately opt-in via its own flag and only validated
+	// for a usable port; enabling it exposes profiling data of the registry process
+	if debug := cache.Debug; debug != nil && debug.Port != nil {
+		for _, msg := range utilvalidation.IsValidPortNum(int(*debug.Port)) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("debug", "port"), *debug.Port, msg))
+		}
+	}
+
+	if cache.HTTPHost != nil {
+		if parsed, err := url.Parse(*cache.HTTPHost); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("httpHost"), *cache.HTTPHost, "httpHost must be a valid URL with an \"http\" or \"https\" scheme"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateMode(fldPath *field.Path, cache registry.RegistryCache) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if cache.Mode != nil && *cache.Mode != registry.ModeStatefulSet && *cache.Mode != registry.ModeDaemonSet {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("mode"), *cache.Mode, []string{registry.ModeStatefulSet, registry.ModeDaemonSet}))
+	}
+
+	if cache.Mode != nil && *cache.Mode == registry.ModeDaemonSet {
+		if cache.HostPort == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("hostPort"), "hostPort must be provided for the DaemonSet mode"))
+		}
+		// the per-node pods are replaced by the DaemonSet rolling update; the StatefulSet
+		// update strategy (and its partition) does not apply to them
+		if cache.UpdateStrategy != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("updateStrategy"), "updateStrategy must not be set for the DaemonSet mode"))
+		}
+	} else if cache.HostPort != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("hostPort"), "hostPort must only be set for the DaemonSet mode"))
+	}
+
+	if cache.HostPort != nil {
+		for _, msg := range utilvalidation.IsValidPortNum(int(*cache.HostPort)) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("hostPort"), *cache.HostPort, msg))
+		}
+	}
+
+	return allErrs
+}
+
+// knownDriverParameters are the tunable parameters of the filesystem storage driver of the
+// distribution. The root directory is deliberately not among them: it is managed by the
+// extension and must not be overridden.
+var knownDriverParameters = []string{"maxthreads"}
+
+func validateStorage(fldPath *field.Path, storage *registry.Storage) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for key, value := range storage.DriverParameters {
+		keyPath := fldPath.Child("driverParameters").Key(key)
+		if key == "rootdirectory" {
+			allErrs = append(allErrs, field.Forbidden(keyPath, "the root directory of the storage driver is managed by the extension and cannot be overridden"))
+			continue
+		}
+		var known bool
+		for _, knownKey := range knownDriverParameters {
+			if key == knownKey {
+				known = true
+			}
+		}
+		if !known {
+			allErrs = append(allErrs, field.NotSupported(keyPath, key, knownDriverParameters))
+			continue
+		}
+		if value == "" {
+			allErrs = append(allErrs, field.Required(keyPath, "a value must be provided"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateService(fldPath *field.Path, service *registry.Service) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if serviceType := service.Type; serviceType != nil && *serviceType != string(corev1.ServiceTypeClusterIP) && *serviceType != string(corev1.ServiceTypeNodePort) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), *serviceType, []string{string(corev1.ServiceTypeClusterIP), string(corev1.ServiceTypeNodePort)}))
+	}
+
+	if affinity := service.SessionAffinity; affinity != nil && *affinity != string(corev1.ServiceAffinityNone) && *affinity != string(corev1.ServiceAffinityClientIP) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("sessionAffinity"), *affinity, []string{string(corev1.ServiceAffinityNone), string(corev1.ServiceAffinityClientIP)}))
+	}
+
+	if timeout := service.SessionAffinityTimeoutSeconds; timeout != nil {
+		if service.SessionAffinity == nil || *service.SessionAffinity != string(corev1.ServiceAffinityClientIP) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("sessionAffinityTimeoutSeconds"), "sessionAffinityTimeoutSeconds must only be set for the \"ClientIP\" session affinity"))
+		}
+		if *timeout <= 0 || *timeout > 86400 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("sessionAffinityTimeoutSeconds"), *timeout, "sessionAffinityTimeoutSeconds must be greater than 0 and at most 86400"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateDNS(fldPath *field.Path, dnsPolicy *string, dnsConfig *corev1.PodDNSConfig) field.ErrorList {
+	var allErrs field.ErrorList
+
+	supportedPolicies := []string{string(corev1.DNSClusterFirst), string(corev1.DNSClusterFirstWithHostNet), string(corev1.DNSDefault), string(corev1.DNSNone)}
+	if dnsPolicy != nil {
+		var supported bool
+		for _, policy := range supportedPolicies {
+			if *dnsPolicy == policy {
+				supported = true
+			}
+		}
+		if !supported {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("dnsPolicy"), *dnsPolicy, supportedPolicies))
+		}
+	}
+
+	if dnsPolicy != nil && *dnsPolicy == string(corev1.DNSNone) && (dnsConfig == nil || len(dnsConfig.Nameservers) == 0) {
+		allErrs = append(allErrs, field.Required(fldPath.Child("dnsConfig", "nameservers"), "at least one nameserver must be provided for the \"None\" DNS policy"))
+	}
+
+	if dnsConfig != nil {
+		for i, nameserver := range dnsConfig.Nameservers {
+			for _, msg := range utilvalidation.IsValidIP(nameserver) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("dnsConfig", "nameservers").Index(i), nameserver, msg))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func validateLivenessProbe(fldPath *field.Path, probe *registry.LivenessProbe) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if probe.Path != nil && !strings.HasPrefix(*probe.Path, "/") {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("path"), *probe.Path, "path must start with a \"/\""))
+	}
+	if probe.InitialDelaySeconds != nil && *probe.InitialDelaySeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("initialDelaySeconds"), *probe.InitialDelaySeconds, "initialDelaySeconds must not be negative"))
+	}
+	for name, value := range map[string]*int32{
+		"periodSeconds":    probe.PeriodSeconds,
+		"timeoutSeconds":   probe.TimeoutSeconds,
+		"failureThreshold": probe.FailureThreshold,
+	} {
+		if value != nil && *value <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(name), *value, name+" must be greater than zero"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateStartupProbe(fldPath *field.Path, probe *registry.StartupProbe) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for name, value := range map[string]*int32{
+		"periodSeconds":    probe.PeriodSeconds,
+		"failureThreshold": probe.FailureThreshold,
+	} {
+		if value != nil && *value <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(name), *value, name+" must be greater than zero"))
+		}
+	}
+
+	return allErrs
+}
+
+func validatePreferredAffinity(fldPath *field.Path, affinity *registry.PreferredAffinity) field.ErrorList {
+	var allErrs field.ErrorList
+
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(&affinity.LabelSelector, fldPath.Child("labelSelector"))...)
+	if len(affinity.LabelSelector.MatchLabels) == 0 && len(affinity.LabelSelector.MatchExpressions) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("labelSelector"), "labelSelector must not be empty; an empty selector would match all pods"))
+	}
+
+	if affinity.Weight != nil && (*affinity.Weight < 1 || *affinity.Weight > 100) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("weight"), *affinity.Weight, "weight must be in the range 1 to 100"))
+	}
+
+	if affinity.TopologyKey != nil {
+		for _, msg := range utilvalidation.IsQualifiedName(*affinity.TopologyKey) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("topologyKey"), *affinity.TopologyKey, msg))
+		}
+	}
+
+	return allErrs
+}
+
+// forbiddenExtraConfigKeys are distribution config keys managed by the extension which must not
+// be overridden by an extraConfig fragment. Each entry is a path into the config document.
+var forbiddenExtraConfigKeys = [][]string{
+	{"storage", "filesystem", "rootdirectory"},
+	{"http", "addr"},
+}
+
+func validateExtraConfig(fldPath *field.Path, extraConfig string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	config := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(extraConfig), &config); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, extraConfig, fmt.Sprintf("extraConfig must be a valid YAML mapping: %v", err)))
+		return allErrs
+	}
+
+	for _, path := range forbiddenExtraConfigKeys {
+		if extraConfigContains(config, path) {
+			allErrs = append(allErrs, field.Forbidden(fldPath, fmt.Sprintf("extraConfig must not set the %s key; it is managed by the extension", strings.Join(path, "."))))
+		}
+	}
+
+	return allErrs
+}
+
+func extraConfigContains(config map[string]interface{}, path []string) bool {
+	value, ok := config[path[0]]
+	if !ok {
+		return false
+	}
+	if len(path) == 1 {
+		return true
+	}
+
+	nested, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	return extraConfigContains(nested, path[1:])
+}
+
+// ValidateRegistryConfigUpdate validates an update of a RegistryConfig against the currently
+// applied one. Kubernetes volumes cannot shrink, so lowering the size of an existing cache is
+// rejected with a descriptive error instead of being silently ignored by the reconciliation.
+// Caches added with the update may use any size because their volume does not exist yet.
+func ValidateRegistryConfigUpdate(newConfig, oldConfig *registry.RegistryConfig, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// an omitted size means the defaulted 10Gi, so the comparison must not skip nil sizes:
+	// dropping an explicit size or shrinking a defaulted cache is a shrink like any other
+	defaultSize := resource.MustParse("10Gi")
+	effectiveSize := func(cache registry.RegistryCache) *resource.Quantity {
+		if cache.Size != nil {
+			return cache.Size
+		}
+		return &defaultSize
+	}
+
+	oldSizes := map[string]*resource.Quantity{}
+	for _, cache := range oldConfig.Caches {
+		oldSizes[cache.Upstream] = effectiveSize(cache)
+	}
+
+	for i, cache := range newConfig.Caches {
+		oldSize, ok := oldSizes[cache.Upstream]
+		if !ok {
+			continue
+		}
+		newSize := effectiveSize(cache)
+		if newSize.Cmp(*oldSize) < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("caches").Index(i).Child("size"), newSize.String(), fmt.Sprintf("volumes cannot shrink: the size of the cache for upstream %q must not be reduced below the current %s", cache.Upstream, oldSize.String())))
+		}
+	}
+
+	return allErrs
+}
+
+func validateUpdateStrategy(fldPath *field.Path, strategy *registry.UpdateStrategy) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if strategy.Type != nil && *strategy.Type != registry.UpdateStrategyTypeRollingUpdate && *strategy.Type != registry.UpdateStrategyTypeOnDelete {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), *strategy.Type, []string{registry.UpdateStrategyTypeRollingUpdate, registry.UpdateStrategyTypeOnDelete}))
+	}
+
+	if strategy.Partition != nil {
+		if *strategy.Partition < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("partition"), *strategy.Partition, "partition must not be negative"))
+		}
+		if strategy.Type != nil && *strategy.Type == registry.UpdateStrategyTypeOnDelete {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("partition"), "partition must not be set for the OnDelete strategy"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateCATrustStore(fldPath *field.Path, trustStore *registry.CATrustStore) field.ErrorList {
+	var allErrs field.ErrorList
+
+	configMapNameSet := trustStore.ConfigMapName != nil && len(*trustStore.ConfigMapName) > 0
+	secretNameSet := trustStore.SecretName != nil && len(*trustStore.SecretName) > 0
+
+	if configMapNameSet == secretNameSet {
+		allErrs = append(allErrs, field.Invalid(fldPath, trustStore, "exactly one of configMapName or secretName must be set"))
+	}
 
 	return allErrs
 }
@@ -52,9 +574,18 @@ func validateUpstream(fldPath *field.Path, upstream string) field.ErrorList {
 		allErrors = append(allErrors, field.Required(fldPath, "upstream must be provided"+form))
 	}
 
-	if strings.HasPrefix(upstream, "https://") || strings.HasPrefix(upstream, "http://") {
+	if strings.HasPrefix(upstream, "https://") {
 		allErrors = append(allErrors, field.Invalid(fldPath, upstream, "upstream must not include a scheme"+form))
 	}
 
+	if strings.HasPrefix(upstream, "http://") {
+		allErrors = append(allErrors, field.Invalid(fldPath, upstream, "upstream must not include a scheme; set insecure to allow plain HTTP to the upstream"+form))
+	}
+
+	// containerd matches mirror hosts literally, so a wildcard upstream would never serve a pull
+	if strings.Contains(upstream, "*") {
+		allErrors = append(allErrors, field.Invalid(fldPath, upstream, "upstream must not contain a wildcard"+form))
+	}
+
 	return allErrors
 }
@@ -15,8 +15,12 @@
 package validation_test
 
 import (
+	"time"
+
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/pointer"
 
@@ -82,23 +86,1003 @@ var _ = Describe("Validation", func() {
 			))
 		})
 
-		It("should deny non-positive cache size", func() {
+		It("should allow a valid path prefix", func() {
+			registryConfig.Caches[0].Upstream = "proxy.example.com"
+			registryConfig.Caches[0].PathPrefix = pointer.String("/dockerhub")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an invalid path prefix", func() {
 			registryConfig.Caches = append(registryConfig.Caches, *registryConfig.Caches[0].DeepCopy())
-			zeroSize := resource.MustParse("0")
-			negativeSize := resource.MustParse("-1Gi")
-			registryConfig.Caches[0].Size = &zeroSize
-			registryConfig.Caches[1].Size = &negativeSize
+			registryConfig.Caches[0].PathPrefix = pointer.String("dockerhub")
+			registryConfig.Caches[1].Upstream = "ghcr.io"
+			registryConfig.Caches[1].PathPrefix = pointer.String("/dockerhub/")
 
 			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
 				PointTo(MatchFields(IgnoreExtras, Fields{
 					"Type":   Equal(field.ErrorTypeInvalid),
-					"Field":  Equal(fldPath.Child("caches").Index(0).Child("size").String()),
-					"Detail": ContainSubstring("size must be a quantity greater than zero"),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("pathPrefix").String()),
+					"Detail": ContainSubstring("pathPrefix must start with"),
 				})),
 				PointTo(MatchFields(IgnoreExtras, Fields{
 					"Type":   Equal(field.ErrorTypeInvalid),
-					"Field":  Equal(fldPath.Child("caches").Index(1).Child("size").String()),
-					"Detail": ContainSubstring("size must be a quantity greater than zero"),
+					"Field":  Equal(fldPath.Child("caches").Index(1).Child("pathPrefix").String()),
+					"Detail": ContainSubstring("pathPrefix must start with"),
+				})),
+			))
+		})
+
+		It("should allow toggling the schema 1 compatibility", func() {
+			registryConfig.Caches[0].Schema1Compatibility = pointer.Bool(true)
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should allow a valid shared ingress configuration", func() {
+			registryConfig.Ingress = &api.Ingress{Host: "registry.example.com"}
+			registryConfig.Caches[0].IngressPathPrefix = pointer.String("/dockerhub")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny a shared ingress without a host", func() {
+			registryConfig.Ingress = &api.Ingress{}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeRequired),
+					"Field": Equal(fldPath.Child("ingress", "host").String()),
+				})),
+			))
+		})
+
+		It("should deny an invalid ingress path prefix", func() {
+			registryConfig.Ingress = &api.Ingress{Host: "registry.example.com"}
+			registryConfig.Caches[0].IngressPathPrefix = pointer.String("dockerhub/")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("ingressPathPrefix").String()),
+					"Detail": ContainSubstring("ingressPathPrefix must start with"),
+				})),
+			))
+		})
+
+		It("should deny duplicate ingress path prefixes", func() {
+			registryConfig.Ingress = &api.Ingress{Host: "registry.example.com"}
+			registryConfig.Caches = append(registryConfig.Caches, *registryConfig.Caches[0].DeepCopy())
+			registryConfig.Caches[1].Upstream = "ghcr.io"
+			registryConfig.Caches[1].IngressPathPrefix = pointer.String("/" + registryConfig.Caches[0].Upstream)
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeDuplicate),
+					"Field": Equal(fldPath.Child("caches").Index(1).Child("ingressPathPrefix").String()),
+				})),
+			))
+		})
+
+		It("should allow valid garbage collection options", func() {
+			registryConfig.Caches[0].GarbageCollection = &api.GarbageCollection{DeleteUntagged: pointer.Bool(true)}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny deleteUntagged combined with readOnly", func() {
+			registryConfig.Caches[0].GarbageCollection = &api.GarbageCollection{
+				DeleteUntagged: pointer.Bool(true),
+				ReadOnly:       pointer.Bool(true),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeForbidden),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("garbageCollection", "deleteUntagged").String()),
+					"Detail": ContainSubstring("deleteUntagged cannot be combined with readOnly"),
+				})),
+			))
+		})
+
+		It("should allow a CA trust store referencing a ConfigMap", func() {
+			registryConfig.Caches[0].CATrustStore = &api.CATrustStore{ConfigMapName: pointer.String("private-cas")}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny a CA trust store referencing both a ConfigMap and a Secret", func() {
+			registryConfig.Caches = append(registryConfig.Caches, *registryConfig.Caches[0].DeepCopy())
+			registryConfig.Caches[1].Upstream = "ghcr.io"
+			registryConfig.Caches[0].CATrustStore = &api.CATrustStore{}
+			registryConfig.Caches[1].CATrustStore = &api.CATrustStore{
+				ConfigMapName: pointer.String("private-cas"),
+				SecretName:    pointer.String("private-cas"),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("caTrustStore").String()),
+					"Detail": ContainSubstring("exactly one of configMapName or secretName must be set"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal(fldPath.Child("caches").Index(1).Child("caTrustStore").String()),
+					"Detail": ContainSubstring("exactly one of configMapName or secretName must be set"),
+				})),
+			))
+		})
+
+		It("should allow a valid update strategy", func() {
+			registryConfig.Caches[0].UpdateStrategy = &api.UpdateStrategy{
+				Type:      pointer.String(api.UpdateStrategyTypeRollingUpdate),
+				Partition: pointer.Int32(1),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an invalid update strategy", func() {
+			registryConfig.Caches = append(registryConfig.Caches, *registryConfig.Caches[0].DeepCopy())
+			registryConfig.Caches[1].Upstream = "ghcr.io"
+			registryConfig.Caches[0].UpdateStrategy = &api.UpdateStrategy{Type: pointer.String("Recreate")}
+			registryConfig.Caches[1].UpdateStrategy = &api.UpdateStrategy{
+				Type:      pointer.String(api.UpdateStrategyTypeOnDelete),
+				Partition: pointer.Int32(-1),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal(fldPath.Child("caches").Index(0).Child("updateStrategy", "type").String()),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal(fldPath.Child("caches").Index(1).Child("updateStrategy", "partition").String()),
+					"Detail": ContainSubstring("partition must not be negative"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeForbidden),
+					"Field":  Equal(fldPath.Child("caches").Index(1).Child("updateStrategy", "partition").String()),
+					"Detail": ContainSubstring("partition must not be set for the OnDelete strategy"),
+				})),
+			))
+		})
+
+		It("should allow a valid DNS configuration", func() {
+			registryConfig.Caches[0].DNSPolicy = pointer.String(string(corev1.DNSNone))
+			registryConfig.Caches[0].DNSConfig = &corev1.PodDNSConfig{Nameservers: []string{"10.0.0.10"}}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an invalid DNS configuration", func() {
+			registryConfig.Caches = append(registryConfig.Caches, *registryConfig.Caches[0].DeepCopy())
+			registryConfig.Caches[1].Upstream = "ghcr.io"
+			registryConfig.Caches[0].DNSPolicy = pointer.String("Custom")
+			registryConfig.Caches[0].DNSConfig = &corev1.PodDNSConfig{Nameservers: []string{"not-an-ip"}}
+			registryConfig.Caches[1].DNSPolicy = pointer.String(string(corev1.DNSNone))
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal(fldPath.Child("caches").Index(0).Child("dnsPolicy").String()),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal(fldPath.Child("caches").Index(0).Child("dnsConfig", "nameservers").Index(0).String()),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeRequired),
+					"Field":  Equal(fldPath.Child("caches").Index(1).Child("dnsConfig", "nameservers").String()),
+					"Detail": ContainSubstring("at least one nameserver must be provided"),
+				})),
+			))
+		})
+
+		It("should allow a valid maintenance window", func() {
+			registryConfig.Caches[0].MaintenanceWindow = &api.MaintenanceWindow{Begin: "220000+0100", End: "230000+0100"}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an invalid maintenance window", func() {
+			registryConfig.Caches[0].MaintenanceWindow = &api.MaintenanceWindow{Begin: "22:00", End: "230000+0100"}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("maintenanceWindow").String()),
+					"Detail": ContainSubstring("maintenanceWindow is invalid"),
+				})),
+			))
+		})
+
+		It("should allow a valid httpHost", func() {
+			registryConfig.Caches[0].HTTPHost = pointer.String("https://registry.example.com:5000")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an invalid httpHost", func() {
+			registryConfig.Caches[0].HTTPHost = pointer.String("registry.example.com")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("httpHost").String()),
+					"Detail": ContainSubstring("httpHost must be a valid URL"),
+				})),
+			))
+		})
+
+		It("should deny a wildcard upstream", func() {
+			registryConfig.Caches[0].Upstream = "*.example.com"
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("upstream").String()),
+					"Detail": ContainSubstring("upstream must not contain a wildcard"),
+				})),
+			))
+		})
+
+		It("should deny two caches for the same upstream", func() {
+			registryConfig.Caches = append(registryConfig.Caches, api.RegistryCache{Upstream: "docker.io"})
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeDuplicate),
+					"Field": Equal(fldPath.Child("caches").Index(1).Child("upstream").String()),
+				})),
+			))
+		})
+
+		It("should allow a valid extraConfig fragment", func() {
+			registryConfig.Caches[0].ExtraConfig = pointer.String("log:\n  level: debug\n")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an extraConfig fragment which is not valid YAML", func() {
+			registryConfig.Caches[0].ExtraConfig = pointer.String("log:\n level: [")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("extraConfig").String()),
+					"Detail": ContainSubstring("extraConfig must be a valid YAML mapping"),
+				})),
+			))
+		})
+
+		It("should deny an extraConfig fragment setting a managed key", func() {
+			registryConfig.Caches[0].ExtraConfig = pointer.String("storage:\n  filesystem:\n    rootdirectory: /somewhere/else\n")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeForbidden),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("extraConfig").String()),
+					"Detail": ContainSubstring("must not set the storage.filesystem.rootdirectory key"),
+				})),
+			))
+		})
+
+		It("should allow a valid preferredAffinity", func() {
+			registryConfig.Caches[0].PreferredAffinity = &api.PreferredAffinity{
+				LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "monolith"}},
+				Weight:        pointer.Int32(50),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an empty preferredAffinity selector and an out-of-range weight", func() {
+			registryConfig.Caches[0].PreferredAffinity = &api.PreferredAffinity{
+				Weight: pointer.Int32(0),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeRequired),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("preferredAffinity", "labelSelector").String()),
+					"Detail": ContainSubstring("labelSelector must not be empty"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("preferredAffinity", "weight").String()),
+					"Detail": ContainSubstring("weight must be in the range 1 to 100"),
+				})),
+			))
+		})
+
+		It("should deny an invalid preferredAffinity selector", func() {
+			registryConfig.Caches[0].PreferredAffinity = &api.PreferredAffinity{
+				LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "not a valid value!"}},
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal(fldPath.Child("caches").Index(0).Child("preferredAffinity", "labelSelector", "matchLabels").String()),
+				})),
+			))
+		})
+
+		It("should allow pinning the upstream IPs for an exact upstream", func() {
+			registryConfig.Caches[0].Proxy = &api.Proxy{PinUpstreamIPs: pointer.Bool(true)}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should allow a valid DaemonSet mode configuration", func() {
+			registryConfig.Caches[0].Mode = pointer.String(api.ModeDaemonSet)
+			registryConfig.Caches[0].HostPort = pointer.Int32(5010)
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny a DaemonSet mode configuration without a hostPort and with an updateStrategy", func() {
+			registryConfig.Caches[0].Mode = pointer.String(api.ModeDaemonSet)
+			registryConfig.Caches[0].UpdateStrategy = &api.UpdateStrategy{Type: pointer.String(api.UpdateStrategyTypeOnDelete)}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeRequired),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("hostPort").String()),
+					"Detail": ContainSubstring("hostPort must be provided for the DaemonSet mode"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeForbidden),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("updateStrategy").String()),
+					"Detail": ContainSubstring("updateStrategy must not be set for the DaemonSet mode"),
+				})),
+			))
+		})
+
+		It("should deny an unsupported mode and a hostPort outside the DaemonSet mode", func() {
+			registryConfig.Caches[0].Mode = pointer.String("Deployment")
+			registryConfig.Caches[0].HostPort = pointer.Int32(5010)
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal(fldPath.Child("caches").Index(0).Child("mode").String()),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeForbidden),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("hostPort").String()),
+					"Detail": ContainSubstring("hostPort must only be set for the DaemonSet mode"),
+				})),
+			))
+		})
+
+		It("should allow a valid imagePullPolicy", func() {
+			registryConfig.Caches[0].ImagePullPolicy = pointer.String(string(corev1.PullNever))
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an unsupported imagePullPolicy", func() {
+			registryConfig.Caches[0].ImagePullPolicy = pointer.String("Sometimes")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal(fldPath.Child("caches").Index(0).Child("imagePullPolicy").String()),
+				})),
+			))
+		})
+
+		It("should allow a valid smokeTest configuration", func() {
+			registryConfig.Caches[0].SmokeTest = &api.SmokeTest{Image: "library/alpine:3.16"}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny a smokeTest without an image", func() {
+			registryConfig.Caches[0].SmokeTest = &api.SmokeTest{}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeRequired),
+					"Field": Equal("providerConfig.caches[0].smokeTest.image"),
+				})),
+			))
+		})
+
+		It("should allow a valid service configuration", func() {
+			registryConfig.Caches[0].Service = &api.Service{
+				Type:                          pointer.String("NodePort"),
+				SessionAffinity:               pointer.String("ClientIP"),
+				SessionAffinityTimeoutSeconds: pointer.Int32(600),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an invalid service configuration", func() {
+			registryConfig.Caches[0].Service = &api.Service{
+				Type:                          pointer.String("LoadBalancer"),
+				SessionAffinity:               pointer.String("Cookie"),
+				SessionAffinityTimeoutSeconds: pointer.Int32(100000),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal("providerConfig.caches[0].service.type"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal("providerConfig.caches[0].service.sessionAffinity"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("providerConfig.caches[0].service.sessionAffinityTimeoutSeconds"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("providerConfig.caches[0].service.sessionAffinityTimeoutSeconds"),
+				})),
+			))
+		})
+
+		It("should deny a session affinity timeout for the \"None\" session affinity", func() {
+			registryConfig.Caches[0].Service = &api.Service{
+				SessionAffinity:               pointer.String("None"),
+				SessionAffinityTimeoutSeconds: pointer.Int32(600),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("providerConfig.caches[0].service.sessionAffinityTimeoutSeconds"),
+				})),
+			))
+		})
+
+		It("should allow the \"Filesystem\" volume mode", func() {
+			registryConfig.Caches[0].VolumeMode = pointer.String("Filesystem")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny the \"Block\" volume mode", func() {
+			registryConfig.Caches[0].VolumeMode = pointer.String("Block")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("providerConfig.caches[0].volumeMode"),
+				})),
+			))
+		})
+
+		It("should deny an unsupported volume mode", func() {
+			registryConfig.Caches[0].VolumeMode = pointer.String("Raw")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal("providerConfig.caches[0].volumeMode"),
+				})),
+			))
+		})
+
+		It("should allow a valid debug configuration", func() {
+			registryConfig.Caches[0].Debug = &api.Debug{
+				Port:         pointer.Int32(6060),
+				PprofEnabled: pointer.Bool(true),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an invalid debug port", func() {
+			registryConfig.Caches[0].Debug = &api.Debug{Port: pointer.Int32(70000)}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("providerConfig.caches[0].debug.port"),
+				})),
+			))
+		})
+
+		It("should allow the \"ReportOnly\" compression policy", func() {
+			registryConfig.Caches[0].CompressionPolicy = pointer.String("ReportOnly")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an unsupported compression policy", func() {
+			registryConfig.Caches[0].CompressionPolicy = pointer.String("Zstd")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal("providerConfig.caches[0].compressionPolicy"),
+				})),
+			))
+		})
+
+		It("should allow known storage driver parameters", func() {
+			registryConfig.Caches[0].Storage = &api.Storage{
+				DriverParameters: map[string]string{"maxthreads": "200"},
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny overriding the storage root directory", func() {
+			registryConfig.Caches[0].Storage = &api.Storage{
+				DriverParameters: map[string]string{"rootdirectory": "/tmp"},
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("providerConfig.caches[0].storage.driverParameters[rootdirectory]"),
+				})),
+			))
+		})
+
+		It("should deny unknown storage driver parameters", func() {
+			registryConfig.Caches[0].Storage = &api.Storage{
+				DriverParameters: map[string]string{"chunksize": "5242880"},
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal("providerConfig.caches[0].storage.driverParameters[chunksize]"),
+				})),
+			))
+		})
+
+		It("should allow upstream readiness for a regular upstream", func() {
+			registryConfig.Caches[0].UpstreamReadiness = pointer.Bool(true)
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should allow a valid pod management policy", func() {
+			registryConfig.Caches[0].PodManagementPolicy = pointer.String("Parallel")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an unsupported pod management policy", func() {
+			registryConfig.Caches[0].PodManagementPolicy = pointer.String("Unordered")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal("providerConfig.caches[0].podManagementPolicy"),
+				})),
+			))
+		})
+
+		It("should allow a valid resource profile", func() {
+			registryConfig.Caches[0].ResourceProfile = pointer.String("medium")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an unknown resource profile", func() {
+			registryConfig.Caches[0].ResourceProfile = pointer.String("xlarge")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal("providerConfig.caches[0].resourceProfile"),
+				})),
+			))
+		})
+
+		It("should deny combining a resource profile with explicit resources", func() {
+			registryConfig.Caches[0].ResourceProfile = pointer.String("small")
+			registryConfig.Caches[0].Resources = &corev1.ResourceRequirements{}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("providerConfig.caches[0].resources"),
+				})),
+			))
+		})
+
+		It("should allow a valid startupProbe configuration", func() {
+			registryConfig.Caches[0].StartupProbe = &api.StartupProbe{
+				PeriodSeconds:    pointer.Int32(15),
+				FailureThreshold: pointer.Int32(60),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny non-positive startupProbe thresholds", func() {
+			registryConfig.Caches[0].StartupProbe = &api.StartupProbe{
+				PeriodSeconds:    pointer.Int32(0),
+				FailureThreshold: pointer.Int32(-1),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("providerConfig.caches[0].startupProbe.periodSeconds"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("providerConfig.caches[0].startupProbe.failureThreshold"),
+				})),
+			))
+		})
+
+		It("should allow an insecure upstream", func() {
+			registryConfig.Caches[0].Upstream = "registry.internal:5000"
+			registryConfig.Caches[0].Insecure = pointer.Bool(true)
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an insecure docker.io", func() {
+			registryConfig.Caches[0].Insecure = pointer.Bool(true)
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("providerConfig.caches[0].insecure"),
+				})),
+			))
+		})
+
+		It("should allow auto-creating caches when suggestions are enabled", func() {
+			registryConfig.SuggestCaches = pointer.Bool(true)
+			registryConfig.AutoCreateCaches = pointer.Bool(true)
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny auto-creating caches without suggestions", func() {
+			registryConfig.AutoCreateCaches = pointer.Bool(true)
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("providerConfig.autoCreateCaches"),
+				})),
+			))
+		})
+
+		It("should allow a dedicated namespace with a quota", func() {
+			registryConfig.Namespace = pointer.String("dedicated-caches")
+			quota := resource.MustParse("100Gi")
+			registryConfig.Quota = &quota
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny deploying the caches into a system namespace", func() {
+			registryConfig.Namespace = pointer.String("kube-system")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("providerConfig.namespace"),
+				})),
+			))
+		})
+
+		It("should deny an invalid namespace name", func() {
+			registryConfig.Namespace = pointer.String("Invalid_Namespace")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("providerConfig.namespace"),
+				})),
+			))
+		})
+
+		It("should deny a non-positive quota", func() {
+			quota := resource.MustParse("0")
+			registryConfig.Quota = &quota
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("providerConfig.quota"),
+				})),
+			))
+		})
+
+		It("should allow valid upload purging tuning", func() {
+			registryConfig.Caches[0].UploadPurging = &api.UploadPurging{
+				Enabled:  pointer.Bool(true),
+				Age:      &metav1.Duration{Duration: 48 * time.Hour},
+				Interval: &metav1.Duration{Duration: 6 * time.Hour},
+				DryRun:   pointer.Bool(true),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny non-positive upload purging durations", func() {
+			registryConfig.Caches[0].UploadPurging = &api.UploadPurging{
+				Age:      &metav1.Duration{Duration: -time.Hour},
+				Interval: &metav1.Duration{Duration: 0},
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("providerConfig.caches[0].uploadPurging.age"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("providerConfig.caches[0].uploadPurging.interval"),
+				})),
+			))
+		})
+
+		It("should allow valid in-shoot monitoring labels", func() {
+			registryConfig.InShootMonitoring = &api.InShootMonitoring{
+				Labels: map[string]string{"release": "in-shoot-prometheus"},
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny invalid in-shoot monitoring labels", func() {
+			registryConfig.InShootMonitoring = &api.InShootMonitoring{
+				Labels: map[string]string{"not a valid key": "value"},
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("providerConfig.inShootMonitoring.labels"),
+				})),
+			))
+		})
+
+		It("should allow a valid mirrorConfigFormat", func() {
+			registryConfig.MirrorConfigFormat = pointer.String(api.MirrorConfigFormatHosts)
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an unsupported mirrorConfigFormat", func() {
+			registryConfig.MirrorConfigFormat = pointer.String("config-path")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal("providerConfig.mirrorConfigFormat"),
+				})),
+			))
+		})
+
+		It("should allow a valid autoscaling configuration", func() {
+			registryConfig.Caches[0].Autoscaling = &api.Autoscaling{
+				MinReplicas:          pointer.Int32(2),
+				MaxReplicas:          4,
+				TargetCPUUtilization: pointer.Int32(80),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an invalid autoscaling configuration", func() {
+			registryConfig.Caches[0].Autoscaling = &api.Autoscaling{
+				MinReplicas: pointer.Int32(5),
+				MaxReplicas: 4,
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("autoscaling", "minReplicas").String()),
+					"Detail": ContainSubstring("must not exceed maxReplicas"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeRequired),
+					"Field": Equal(fldPath.Child("caches").Index(0).Child("autoscaling").String()),
+				})),
+			))
+		})
+
+		It("should deny autoscaling in DaemonSet mode", func() {
+			registryConfig.Caches[0].Mode = pointer.String(api.ModeDaemonSet)
+			registryConfig.Caches[0].HostPort = pointer.Int32(5001)
+			registryConfig.Caches[0].Autoscaling = &api.Autoscaling{
+				MaxReplicas:          4,
+				TargetCPUUtilization: pointer.Int32(80),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal(fldPath.Child("caches").Index(0).Child("autoscaling").String()),
+				})),
+			))
+		})
+
+		It("should allow a valid cache group", func() {
+			registryConfig.Caches[0].Group = pointer.String("base-images")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny a cache group that is not a DNS label", func() {
+			registryConfig.Caches[0].Group = pointer.String("Base Images")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal(fldPath.Child("caches").Index(0).Child("group").String()),
+				})),
+			))
+		})
+
+		It("should allow a valid storage tiering configuration", func() {
+			registryConfig.StorageTiering = &api.StorageTiering{
+				HotStorageClassName:  "ssd",
+				ColdStorageClassName: "hdd",
+				MigrationWindow: &api.MaintenanceWindow{
+					Begin: "220000+0000",
+					End:   "230000+0000",
+				},
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an invalid storage tiering configuration", func() {
+			hotBytesThreshold := resource.MustParse("-1Gi")
+			registryConfig.StorageTiering = &api.StorageTiering{
+				HotStorageClassName:  "ssd",
+				ColdStorageClassName: "ssd",
+				HotBytesThreshold:    &hotBytesThreshold,
+				MigrationWindow: &api.MaintenanceWindow{
+					Begin: "2200",
+					End:   "230000+0000",
+				},
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal(fldPath.Child("storageTiering", "coldStorageClassName").String()),
+					"Detail": ContainSubstring("different storage classes"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal(fldPath.Child("storageTiering", "hotBytesThreshold").String()),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal(fldPath.Child("storageTiering", "migrationWindow").String()),
+				})),
+			))
+		})
+
+		It("should allow a valid fsGroup configuration", func() {
+			registryConfig.Caches[0].FSGroup = pointer.Int64(1000)
+			registryConfig.Caches[0].FSGroupChangePolicy = pointer.String(string(corev1.FSGroupChangeOnRootMismatch))
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an invalid fsGroup configuration", func() {
+			registryConfig.Caches[0].FSGroup = pointer.Int64(-1)
+			registryConfig.Caches[0].FSGroupChangePolicy = pointer.String("Never")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("fsGroup").String()),
+					"Detail": ContainSubstring("fsGroup must be in the range"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal(fldPath.Child("caches").Index(0).Child("fsGroupChangePolicy").String()),
+				})),
+			))
+		})
+
+		It("should deny non-positive cache size", func() {
+			registryConfig.Caches = append(registryConfig.Caches, *registryConfig.Caches[0].DeepCopy())
+			registryConfig.Caches[1].Upstream = "ghcr.io"
+			zeroSize := resource.MustParse("0")
+			negativeSize := resource.MustParse("-1Gi")
+			registryConfig.Caches[0].Size = &zeroSize
+			registryConfig.Caches[1].Size = &negativeSize
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("size").String()),
+					"Detail": ContainSubstring("size must be a quantity greater than zero"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal(fldPath.Child("caches").Index(1).Child("size").String()),
+					"Detail": ContainSubstring("size must be a quantity greater than zero"),
+				})),
+			))
+		})
+	})
+
+	Describe("#ValidateRegistryConfigUpdate", func() {
+		var oldRegistryConfig *api.RegistryConfig
+
+		BeforeEach(func() {
+			oldRegistryConfig = registryConfig.DeepCopy()
+		})
+
+		It("should allow the same size", func() {
+			Expect(ValidateRegistryConfigUpdate(registryConfig, oldRegistryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should allow growing the size", func() {
+			newSize := resource.MustParse("10Gi")
+			registryConfig.Caches[0].Size = &newSize
+
+			Expect(ValidateRegistryConfigUpdate(registryConfig, oldRegistryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should allow any size for a new cache", func() {
+			smallSize := resource.MustParse("1Gi")
+			registryConfig.Caches = append(registryConfig.Caches, api.RegistryCache{
+				Upstream: "ghcr.io",
+				Size:     &smallSize,
+			})
+
+			Expect(ValidateRegistryConfigUpdate(registryConfig, oldRegistryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny shrinking a cache that relied on the defaulted size", func() {
+			oldRegistryConfig.Caches[0].Size = nil
+			newSize := resource.MustParse("5Gi")
+			registryConfig.Caches[0].Size = &newSize
+
+			Expect(ValidateRegistryConfigUpdate(registryConfig, oldRegistryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("size").String()),
+					"Detail": ContainSubstring("volumes cannot shrink"),
+				})),
+			))
+		})
+
+		It("should deny dropping an explicit size above the default", func() {
+			oldSize := resource.MustParse("20Gi")
+			oldRegistryConfig.Caches[0].Size = &oldSize
+			registryConfig.Caches[0].Size = nil
+
+			Expect(ValidateRegistryConfigUpdate(registryConfig, oldRegistryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("size").String()),
+					"Detail": ContainSubstring("volumes cannot shrink"),
+				})),
+			))
+		})
+
+		It("should deny shrinking the size of an existing cache", func() {
+			newSize := resource.MustParse("1Gi")
+			registryConfig.Caches[0].Size = &newSize
+
+			Expect(ValidateRegistryConfigUpdate(registryConfig, oldRegistryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal(fldPath.Child("caches").Index(0).Child("size").String()),
+					"Detail": ContainSubstring("volumes cannot shrink"),
 				})),
 			))
 		})
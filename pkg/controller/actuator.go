@@ -17,6 +17,10 @@ package controller
 import (
 	"context"
 	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -30,23 +34,34 @@ import (
 	"github.com/gardener/gardener/extensions/pkg/controller"
 	"github.com/gardener/gardener/extensions/pkg/controller/extension"
 	"github.com/gardener/gardener/extensions/pkg/util"
+	v1beta1helper "github.com/gardener/gardener/pkg/apis/core/v1beta1/helper"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/gardener/gardener/pkg/utils/timewindow"
+	appsv1 "k8s.io/api/apps/v1"
 
 	"github.com/gardener/gardener/pkg/client/kubernetes"
 	"github.com/gardener/gardener/pkg/utils/managedresources"
 	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // NewActuator returns an actuator responsible for Extension resources.
 func NewActuator(config config.Configuration) extension.Actuator {
 	return &actuator{
-		config: config,
+		config:               config,
+		now:                  time.Now,
+		lookupHost:           net.DefaultResolver.LookupHost,
+		pulledBytes:          queryPulledBytes,
+		gcRunning:            activeGCJobs,
+		volumeAvailableBytes: queryVolumeAvailableBytes,
 	}
 }
 
@@ -54,6 +69,18 @@ type actuator struct {
 	client  client.Client
 	decoder runtime.Decoder
 	config  config.Configuration
+	// now returns the current time and can be replaced in tests.
+	now func() time.Time
+	// lookupHost resolves a hostname to IPs and can be replaced in tests.
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+	// pulledBytes queries the cumulative bytes served per upstream and can be replaced in tests.
+	pulledBytes func(ctx context.Context, namespace string) (map[string]int64, error)
+	// gcRunning reports the caches with a garbage collection Job currently in progress and can
+	// be replaced in tests.
+	gcRunning func(ctx context.Context, c client.Client, cacheNamespace string) (map[string]bool, error)
+	// volumeAvailableBytes queries the free bytes per cache volume claim and can be replaced
+	// in tests.
+	volumeAvailableBytes func(ctx context.Context, namespace, cacheNamespace string) (map[string]int64, error)
 }
 
 // InjectClient injects the controller runtime client into the reconciler.
@@ -68,12 +95,18 @@ func (a *actuator) InjectScheme(scheme *runtime.Scheme) error {
 	return nil
 }
 
+// evictRepositoryAnnotation requests a one-off eviction of a repository's cached content, in
+// the form "<upstream>/<repository>". The annotation stays on the Extension resource until the
+// operator removes it; a new eviction Job is only generated for a different target.
+const evictRepositoryAnnotation = "registry-cache.extensions.gardener.cloud/evict-repository"
+
+// evictRepositoryRegexp matches plain repository names as accepted by the distribution
+// registry. The eviction target ends up in the shell script of the eviction Job, so anything
+// else is rejected.
+var evictRepositoryRegexp = regexp.MustCompile(`^[a-z0-9]+(?:[._-][a-z0-9]+)*(?:/[a-z0-9]+(?:[._-][a-z0-9]+)*)*$`)
+
 // Reconcile the Extension resource.
 func (a *actuator) Reconcile(ctx context.Context, log logr.Logger, ex *extensionsv1alpha1.Extension) error {
-	if ex.Spec.ProviderConfig == nil {
-		return nil
-	}
-
 	namespace := ex.GetNamespace()
 
 	cluster, err := controller.GetCluster(ctx, a.client, namespace)
@@ -82,15 +115,33 @@ func (a *actuator) Reconcile(ctx context.Context, log logr.Logger, ex *extension
 	}
 
 	registryConfig := &v1alpha1.RegistryConfig{}
-	if _, _, err := a.decoder.Decode(ex.Spec.ProviderConfig.Raw, nil, registryConfig); err != nil {
-		return fmt.Errorf("failed to decode provider config: %w", err)
+	if ex.Spec.ProviderConfig != nil {
+		if _, _, err := a.decoder.Decode(ex.Spec.ProviderConfig.Raw, nil, registryConfig); err != nil {
+			return fmt.Errorf("failed to decode provider config: %w", err)
+		}
 	}
 
-	if err := a.createResources(ctx, log, registryConfig, cluster, namespace); err != nil {
+	applyDefaultCaches(a.config.DefaultCaches, registryConfig, cluster.Shoot.Labels)
+
+	if ex.Spec.ProviderConfig == nil && len(registryConfig.Caches) == 0 {
+		return nil
+	}
+
+	if err := a.createResources(ctx, log, registryConfig, cluster, namespace, ex.GetAnnotations()[evictRepositoryAnnotation], ex.GetAnnotations()[garbageCollectAnnotation]); err != nil {
 		return err
 	}
 
-	return nil
+	if ex.GetAnnotations()[garbageCollectAnnotation] != "" {
+		_, shootClient, err := util.NewClientForShoot(ctx, a.client, cluster.ObjectMeta.Name, client.Options{}, extensionsconfig.RESTOptions{})
+		if err != nil {
+			return fmt.Errorf("shoot client cannot be crated: %w", err)
+		}
+		if err := a.clearFinishedManualGC(ctx, log, shootClient, ex, registryCacheNamespace(registryConfig)); err != nil {
+			return err
+		}
+	}
+
+	return a.updateStatus(ctx, log, ex, cluster, registryConfig)
 }
 
 // Delete the Extension resource.
@@ -108,7 +159,7 @@ func (a *actuator) Migrate(ctx context.Context, log logr.Logger, ex *extensionsv
 	return nil
 }
 
-func (a *actuator) createResources(ctx context.Context, log logr.Logger, registryConfig *v1alpha1.RegistryConfig, cluster *controller.Cluster, namespace string) error {
+func (a *actuator) createResources(ctx context.Context, log logr.Logger, registryConfig *v1alpha1.RegistryConfig, cluster *controller.Cluster, namespace, evictTarget, gcTarget string) error {
 	registryImage, err := imagevector.ImageVector().FindImage("registry")
 	if err != nil {
 		return fmt.Errorf("failed to find registry image: %w", err)
@@ -118,48 +169,259 @@ func (a *actuator) createResources(ctx context.Context, log logr.Logger, registr
 		return fmt.Errorf("failed to find ensurer image: %w", err)
 	}
 
-	objects := []client.Object{
-		&corev1.Namespace{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: registryCacheNamespaceName,
-			},
-		},
+	_, shootClient, err := util.NewClientForShoot(ctx, a.client, cluster.ObjectMeta.Name, client.Options{}, extensionsconfig.RESTOptions{})
+	if err != nil {
+		return fmt.Errorf("shoot client cannot be crated: %w", err)
+	}
+
+	cacheNamespace := registryCacheNamespace(registryConfig)
+
+	if err := verifyCATrustStores(ctx, shootClient, registryConfig, cacheNamespace); err != nil {
+		return err
+	}
+
+	secret, err := httpSecret(ctx, shootClient, cacheNamespace)
+	if err != nil {
+		return err
+	}
+
+	objects := sharedObjects(cacheNamespace, registryConfig.Quota)
+	objects = append(objects, secret)
+
+	if pointer.BoolDeref(registryConfig.AutoCreateCaches, false) {
+		workloadPods := &corev1.PodList{}
+		if err := shootClient.List(ctx, workloadPods); err != nil {
+			return fmt.Errorf("shoot pods cannot be read for auto-creating caches: %w", err)
+		}
+		for _, upstream := range suggestedUpstreams(registryConfig, workloadPods.Items) {
+			cache := v1alpha1.RegistryCache{Upstream: upstream}
+			v1alpha1.SetDefaults_RegistryCache(&cache)
+			registryConfig.Caches = append(registryConfig.Caches, cache)
+		}
+	}
+
+	if pointer.BoolDeref(registryConfig.CacheSystemImages, false) {
+		systemPods := &corev1.PodList{}
+		if err := shootClient.List(ctx, systemPods, client.InNamespace(metav1.NamespaceSystem)); err != nil {
+			return fmt.Errorf("shoot system pods cannot be read for caching system images: %w", err)
+		}
+		for _, upstream := range systemImageUpstreams(registryConfig, systemPods.Items) {
+			cache := v1alpha1.RegistryCache{Upstream: upstream}
+			v1alpha1.SetDefaults_RegistryCache(&cache)
+			registryConfig.Caches = append(registryConfig.Caches, cache)
+		}
+	}
+
+	evictUpstream, evictRepository, err := resolveEvictionTarget(evictTarget, registryConfig)
+	if err != nil {
+		return err
+	}
+
+	gcUpstream, err := resolveGCTarget(gcTarget, registryConfig)
+	if err != nil {
+		return err
+	}
+
+	var gcRunning map[string]bool
+	for _, cache := range registryConfig.Caches {
+		if pointer.BoolDeref(cache.GarbageCollectionEnabled, false) {
+			running, err := a.gcRunning(ctx, shootClient, cacheNamespace)
+			if err != nil {
+				// without the Job state the lock cannot be observed; rather than blocking all
+				// reconciliations on it, the rollout proceeds
+				log.Error(err, "could not list running garbage collection jobs")
+			}
+			gcRunning = running
+			break
+		}
+	}
+
+	var freeVolumeBytes map[string]int64
+	for _, cache := range registryConfig.Caches {
+		if cache.GarbageCollection != nil && cache.GarbageCollection.MinFreeBytes != nil {
+			free, err := a.volumeAvailableBytes(ctx, namespace, cacheNamespace)
+			if err != nil {
+				// the free-space safety valve is best-effort; while the kubelet volume stats
+				// cannot be read, the caches keep accepting new content
+				log.Error(err, "could not query available volume bytes from prometheus")
+			}
+			freeVolumeBytes = free
+			break
+		}
+	}
+
+	var tieringPulledBytes map[string]int64
+	if registryConfig.StorageTiering != nil {
+		bytes, err := a.pulledBytes(ctx, namespace)
+		if err != nil {
+			// tier selection is best-effort; caches keep their current storage class while
+			// the traffic metrics cannot be read
+			log.Error(err, "could not query pulled bytes for storage tiering")
+		}
+		tieringPulledBytes = bytes
 	}
 
 	for _, cache := range registryConfig.Caches {
 		c := registryCache{
-			Namespace:                registryCacheNamespaceName,
+			Namespace:                cacheNamespace,
 			Upstream:                 cache.Upstream,
+			Insecure:                 pointer.BoolDeref(cache.Insecure, false),
 			VolumeSize:               *cache.Size,
+			VolumeMode:               cache.VolumeMode,
+			Group:                    pointer.StringDeref(cache.Group, ""),
 			GarbageCollectionEnabled: *cache.GarbageCollectionEnabled,
+			GarbageCollection:        cache.GarbageCollection,
+			UploadPurging:            cache.UploadPurging,
+			CATrustStore:             cache.CATrustStore,
+			UpdateStrategy:           cache.UpdateStrategy,
+			Autoscaling:              cache.Autoscaling,
+			Mode:                     pointer.StringDeref(cache.Mode, v1alpha1.ModeStatefulSet),
+			HostPort:                 pointer.Int32Deref(cache.HostPort, 0),
+			PathPrefix:               pointer.StringDeref(cache.PathPrefix, ""),
+			DNSPolicy:                cache.DNSPolicy,
+			DNSConfig:                cache.DNSConfig,
+			ImagePullPolicy:          cache.ImagePullPolicy,
+			FSGroup:                  cache.FSGroup,
+			FSGroupChangePolicy:      cache.FSGroupChangePolicy,
+			HTTPHost:                 pointer.StringDeref(cache.HTTPHost, ""),
+			LivenessProbe:            cache.LivenessProbe,
+			StartupProbe:             cache.StartupProbe,
+			ExtraConfig:              pointer.StringDeref(cache.ExtraConfig, ""),
+			PreferredAffinity:        cache.PreferredAffinity,
+			NodeAffinity:             cache.NodeAffinity,
+			FallbackToUpstream:       pointer.BoolDeref(cache.FallbackToUpstream, true),
+			SmokeTest:                cache.SmokeTest,
+			Service:                  cache.Service,
+			Debug:                    cache.Debug,
+			CompressionPolicy:        cache.CompressionPolicy,
+			Storage:                  cache.Storage,
+			UpstreamReadiness:        pointer.BoolDeref(cache.UpstreamReadiness, false),
+			ResourceProfile:          cache.ResourceProfile,
+			Resources:                cache.Resources,
+			PodManagementPolicy:      cache.PodManagementPolicy,
 			RegistryImage:            registryImage,
 		}
+		if cache.ServiceAccountName != nil {
+			c.ServiceAccountName = *cache.ServiceAccountName
+		}
+
+		if evictRepository != "" && cache.Upstream == evictUpstream {
+			c.EvictRepository = evictRepository
+			log.Info("evicting repository from cache", "upstream", cache.Upstream, "repository", evictRepository)
+		}
+
+		if gcUpstream != "" && cache.Upstream == gcUpstream {
+			c.ManualGC = true
+			log.Info("starting manual garbage collection run", "upstream", cache.Upstream)
+		}
+
+		if cache.GarbageCollection != nil && cache.GarbageCollection.MinFreeBytes != nil {
+			if free, ok := freeVolumeBytes[cacheVolumeClaimName(cacheObjectName(cache.Upstream))]; ok && blockCacheMissFetches(free, cache.GarbageCollection.MinFreeBytes) {
+				c.VolumeBelowMinFree = true
+				log.Info("cache volume is below the configured minimum free space, switching the registry to read-only", "upstream", cache.Upstream, "freeBytes", free)
+			}
+		}
+
+		if cache.HTTP != nil {
+			c.HTTP2Enabled = cache.HTTP.HTTP2
+		}
+
+		c.Schema1Compatibility = cache.Schema1Compatibility
+
+		if cache.Proxy != nil && pointer.BoolDeref(cache.Proxy.PinUpstreamIPs, false) {
+			// the IPs are resolved once per reconciliation, so IP changes of the upstream are
+			// picked up with the periodic reconcile
+			ips, err := a.lookupHost(ctx, upstreamRegistryHost(cache.Upstream))
+			if err != nil {
+				a.emitCacheEvents(ctx, log, shootClient, cacheNamespace, cache.Upstream, []cacheMilestone{{
+					EventType: corev1.EventTypeWarning,
+					Reason:    eventReasonUpstreamUnreachable,
+					Message:   fmt.Sprintf("Upstream %s cannot be resolved: %v", cache.Upstream, err),
+				}})
+				return fmt.Errorf("failed to resolve upstream %q for pinning its IPs: %w", cache.Upstream, err)
+			}
+			c.UpstreamIPs = ips
+		}
+
+		if c.Mode == v1alpha1.ModeStatefulSet {
+			tierObjects, err := a.reconcileStorageTier(ctx, log, shootClient, cache, registryConfig.StorageTiering, tieringPulledBytes, &c)
+			if err != nil {
+				return err
+			}
+			objects = append(objects, tierObjects...)
+		}
 
 		os, err := c.Ensure()
 		if err != nil {
 			return err
 		}
 
+		for _, obj := range os {
+			desiredStatefulSet, ok := obj.(*appsv1.StatefulSet)
+			if !ok {
+				continue
+			}
+			deployed := &appsv1.StatefulSet{}
+			if err := shootClient.Get(ctx, client.ObjectKey{Name: desiredStatefulSet.Name, Namespace: cacheNamespace}, deployed); err != nil {
+				if apierrors.IsNotFound(err) {
+					// the StatefulSet is created with this reconciliation, so there is no drift
+					driftGauge.WithLabelValues(cache.Upstream).Set(0)
+					a.emitCacheEvents(ctx, log, shootClient, cacheNamespace, cache.Upstream, cacheMilestones(cache.Upstream, desiredStatefulSet, nil))
+				}
+				continue
+			}
+			serializeGCAndUpdate(desiredStatefulSet, deployed, gcRunning[desiredStatefulSet.Name])
+			drift := float64(0)
+			if statefulSetDrifted(desiredStatefulSet, deployed) {
+				drift = 1
+			}
+			driftGauge.WithLabelValues(cache.Upstream).Set(drift)
+			a.emitCacheEvents(ctx, log, shootClient, cacheNamespace, cache.Upstream, cacheMilestones(cache.Upstream, desiredStatefulSet, deployed))
+		}
+
+		deferred, err := deferDisruptiveChanges(cache, a.now())
+		if err != nil {
+			return err
+		}
+		if deferred {
+			existing := &appsv1.StatefulSet{}
+			if err := shootClient.Get(ctx, client.ObjectKey{Name: c.Name, Namespace: cacheNamespace}, existing); err == nil {
+				// keep the currently deployed spec until the maintenance window; new caches are
+				// still created because their StatefulSet does not exist yet
+				for _, obj := range os {
+					if statefulSet, ok := obj.(*appsv1.StatefulSet); ok {
+						statefulSet.Spec = existing.Spec
+					}
+				}
+			} else if !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+
 		objects = append(objects, os...)
 	}
 
+	if registryConfig.Ingress != nil {
+		objects = append(objects, cacheIngress(cacheNamespace, registryConfig.Ingress, registryConfig.Caches))
+	}
+
 	resources, err := managedresources.NewRegistry(kubernetes.SeedScheme, kubernetes.SeedCodec, kubernetes.SeedSerializer).AddAllAndSerialize(objects...)
 	if err != nil {
 		return err
 	}
 
+	if registryConfig.InShootMonitoring != nil {
+		// the prometheus-operator API is not part of the seed scheme, so the PodMonitor is
+		// added as a pre-rendered manifest, see inShootPodMonitorYAML
+		resources[fmt.Sprintf("podmonitor__%s__%s.yaml", cacheNamespace, inShootPodMonitorName)] = []byte(inShootPodMonitorYAML(cacheNamespace, registryConfig.InShootMonitoring.Labels))
+	}
+
 	// create ManagedResource for the registryCache
 	err = a.createManagedResources(ctx, v1alpha1.RegistryResourceName, namespace, resources, nil)
 	if err != nil {
 		return err
 	}
 
-	// get service IPs from shoot
-	_, shootClient, err := util.NewClientForShoot(ctx, a.client, cluster.ObjectMeta.Name, client.Options{}, extensionsconfig.RESTOptions{})
-	if err != nil {
-		return fmt.Errorf("shoot client cannot be crated: %w", err)
-	}
-
 	selector := labels.NewSelector()
 	r, err := labels.NewRequirement(registryCacheServiceUpstreamLabel, selection.Exists, nil)
 	if err != nil {
@@ -169,7 +431,7 @@ func (a *actuator) createResources(ctx context.Context, log logr.Logger, registr
 
 	// get all registry cache services
 	services := &corev1.ServiceList{}
-	if err := shootClient.List(ctx, services, client.InNamespace(registryCacheNamespaceName), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+	if err := shootClient.List(ctx, services, client.InNamespace(cacheNamespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
 		log.Error(err, "could not read services from shoot")
 		return err
 	}
@@ -179,16 +441,23 @@ func (a *actuator) createResources(ctx context.Context, log logr.Logger, registr
 	}
 
 	e := criEnsurer{
-		Namespace:          registryCacheNamespaceName,
-		CRIEnsurerImage:    ensurerImage,
-		ReferencedServices: services,
+		Namespace:              cacheNamespace,
+		CRIEnsurerImage:        ensurerImage,
+		ReferencedServices:     services,
+		DisableMirrorInjection: a.config.DisableMirrorInjection,
+		MirrorConfigFormat:     pointer.StringDeref(registryConfig.MirrorConfigFormat, ""),
 	}
 
 	os, err := e.Ensure()
 	if err != nil {
 		return err
 	}
-	objects = []client.Object{}
+	endpoints, err := endpointsConfigMap(services)
+	if err != nil {
+		return err
+	}
+
+	objects = []client.Object{endpoints}
 	objects = append(objects, os...)
 
 	resources, err = managedresources.NewRegistry(kubernetes.SeedScheme, kubernetes.SeedCodec, kubernetes.SeedSerializer).AddAllAndSerialize(objects...)
@@ -201,12 +470,176 @@ func (a *actuator) createResources(ctx context.Context, log logr.Logger, registr
 		return err
 	}
 
+	monitoring := &monitoringConfig{
+		ShootName:      cluster.Shoot.Name,
+		Region:         cluster.Shoot.Spec.Region,
+		Provider:       cluster.Shoot.Spec.Provider.Type,
+		CacheNamespace: cacheNamespace,
+	}
+	if a.config.Monitoring != nil {
+		monitoring.DropLabels = a.config.Monitoring.DropMetricLabels
+		monitoring.MetricsAuthSecretName = pointer.StringDeref(a.config.Monitoring.MetricsAuthSecretName, "")
+		if a.config.Monitoring.HonorTimestamps != nil {
+			monitoring.HonorTimestamps = strconv.FormatBool(*a.config.Monitoring.HonorTimestamps)
+		}
+		if a.config.Monitoring.ScrapeTimeout != nil {
+			monitoring.ScrapeTimeout = a.config.Monitoring.ScrapeTimeout.Duration.String()
+		}
+		monitoring.AlertLabels = a.config.Monitoring.AlertLabels
+		monitoring.AlertRetention = a.config.Monitoring.AlertRetention
+	}
+	for _, cache := range registryConfig.Caches {
+		if pointer.BoolDeref(cache.LongTermMetrics, false) {
+			monitoring.LongTermUpstreams = append(monitoring.LongTermUpstreams, cache.Upstream)
+		}
+	}
+	if err := a.deployMonitoringConfig(ctx, monitoring, namespace); err != nil {
+		return fmt.Errorf("failed to deploy monitoring config: %w", err)
+	}
+
+	// pruning is best-effort and must not block the cache deployment
+	if err := a.pruneOrphanedPVCs(ctx, log, shootClient, registryConfig); err != nil {
+		log.Error(err, "could not prune orphaned cache volume claims")
+	}
+
 	return nil
 }
 
+// deferDisruptiveChanges reports whether disruptive changes to the given cache must be deferred
+// because the given time is outside its maintenance window.
+func deferDisruptiveChanges(cache v1alpha1.RegistryCache, now time.Time) (bool, error) {
+	if cache.MaintenanceWindow == nil {
+		return false, nil
+	}
+
+	window, err := timewindow.ParseMaintenanceTimeWindow(cache.MaintenanceWindow.Begin, cache.MaintenanceWindow.End)
+	if err != nil {
+		return false, err
+	}
+
+	return !window.Contains(now), nil
+}
+
+// applyDefaultCaches appends a cache with default settings for every upstream of the default
+// caches policy if the shoot carries the configured label. A cache explicitly configured by the
+// shoot for the same upstream takes precedence over the default cache.
+func applyDefaultCaches(defaults *config.DefaultCachesConfig, registryConfig *v1alpha1.RegistryConfig, shootLabels map[string]string) {
+	if defaults == nil {
+		return
+	}
+	value, ok := shootLabels[defaults.ShootLabel]
+	if !ok || (defaults.ShootLabelValue != nil && value != *defaults.ShootLabelValue) {
+		return
+	}
+
+	for _, upstream := range defaults.Upstreams {
+		if upstreamCached(registryConfig, upstream) {
+			continue
+		}
+		cache := v1alpha1.RegistryCache{Upstream: upstream}
+		v1alpha1.SetDefaults_RegistryCache(&cache)
+		registryConfig.Caches = append(registryConfig.Caches, cache)
+	}
+}
+
+// registryCacheNamespace returns the shoot namespace the caches are deployed to.
+func registryCacheNamespace(registryConfig *v1alpha1.RegistryConfig) string {
+	return pointer.StringDeref(registryConfig.Namespace, registryCacheNamespaceName)
+}
+
+// sharedObjects returns the shoot objects shared by all registry caches.
+func sharedObjects(namespace string, quota *resource.Quantity) []client.Object {
+	objects := []client.Object{
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: namespace,
+			},
+		},
+		&corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      registryCacheServiceAccountName,
+				Namespace: namespace,
+			},
+			// the cache pods do not need API access, so no token is mounted and no RBAC is bound
+			AutomountServiceAccountToken: pointer.Bool(false),
+		},
+	}
+
+	if quota != nil {
+		objects = append(objects, &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      registryCacheNamespaceName,
+				Namespace: namespace,
+			},
+			Spec: corev1.ResourceQuotaSpec{
+				// bounds the total volume storage all caches together may request; the quota
+				// does not shrink already bound volumes, it only rejects new claims
+				Hard: corev1.ResourceList{
+					corev1.ResourceRequestsStorage: *quota,
+				},
+			},
+		})
+	}
+
+	return objects
+}
+
+// verifyCATrustStores checks that the CA trust store objects referenced by the registry
+// configuration actually exist in the shoot.
+func verifyCATrustStores(ctx context.Context, shootClient client.Client, registryConfig *v1alpha1.RegistryConfig, namespace string) error {
+	for _, cache := range registryConfig.Caches {
+		if cache.CATrustStore == nil {
+			continue
+		}
+
+		switch {
+		case cache.CATrustStore.ConfigMapName != nil:
+			configMap := &corev1.ConfigMap{}
+			if err := shootClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: *cache.CATrustStore.ConfigMapName}, configMap); err != nil {
+				return fmt.Errorf("CA trust store ConfigMap %q referenced by cache for upstream %q cannot be read: %w", *cache.CATrustStore.ConfigMapName, cache.Upstream, err)
+			}
+		case cache.CATrustStore.SecretName != nil:
+			secret := &corev1.Secret{}
+			if err := shootClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: *cache.CATrustStore.SecretName}, secret); err != nil {
+				return fmt.Errorf("CA trust store Secret %q referenced by cache for upstream %q cannot be read: %w", *cache.CATrustStore.SecretName, cache.Upstream, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveEvictionTarget splits the eviction annotation value into the upstream and the
+// repository to evict. The upstream must have a configured cache and the repository must be a
+// plain repository name, so that the annotation cannot smuggle anything into the eviction Job
+// script.
+func resolveEvictionTarget(target string, registryConfig *v1alpha1.RegistryConfig) (string, string, error) {
+	if target == "" {
+		return "", "", nil
+	}
+
+	upstream, repository, ok := strings.Cut(target, "/")
+	if !ok || !evictRepositoryRegexp.MatchString(repository) {
+		return "", "", fmt.Errorf("eviction target %q is not of the form <upstream>/<repository>", target)
+	}
+
+	for _, cache := range registryConfig.Caches {
+		if cache.Upstream == upstream {
+			return upstream, repository, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("no cache is configured for upstream %q to evict %q from", upstream, repository)
+}
+
 func (a *actuator) deleteResources(ctx context.Context, log logr.Logger, namespace string) error {
 	log.Info("deleting managed resource for registry cache")
 
+	monitoringConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: monitoringConfigMapName, Namespace: a.monitoringNamespace(namespace)}}
+	if err := client.IgnoreNotFound(a.client.Delete(ctx, monitoringConfigMap)); err != nil {
+		return err
+	}
+
 	if err := managedresources.Delete(ctx, a.client, namespace, v1alpha1.RegistryResourceName, false); err != nil {
 		return err
 	}
@@ -224,19 +657,144 @@ func (a *actuator) createManagedResources(ctx context.Context, name, namespace s
 					DeletePersistentVolumeClaims(true)
 	)
 
-	if err := secret.Reconcile(ctx); err != nil {
+	if err := reconcileWithRetry(ctx, secret.Reconcile); err != nil {
 		return fmt.Errorf("could not create or update secret of managed resources: %w", err)
 	}
 
-	if err := managedResource.Reconcile(ctx); err != nil {
+	if err := reconcileWithRetry(ctx, managedResource.Reconcile); err != nil {
 		return fmt.Errorf("could not create or update managed resource: %w", err)
 	}
 
 	return nil
 }
 
-func (a *actuator) updateStatus(ctx context.Context, ex *extensionsv1alpha1.Extension, _ *v1alpha1.RegistryConfig) error {
+// reconcileWithRetry retries the given idempotent create-or-patch call with backoff as long as
+// the seed API server returns a conflict or another transient error. Permanent errors are
+// returned immediately.
+func reconcileWithRetry(ctx context.Context, reconcile func(context.Context) error) error {
+	return retry.OnError(retry.DefaultBackoff, isRetriableAPIError, func() error {
+		return reconcile(ctx)
+	})
+}
+
+func isRetriableAPIError(err error) bool {
+	return apierrors.IsConflict(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsInternalError(err)
+}
+
+// updateStatus reports per-cache statistics observed in the shoot as provider status of the
+// Extension resource.
+func (a *actuator) updateStatus(ctx context.Context, log logr.Logger, ex *extensionsv1alpha1.Extension, cluster *controller.Cluster, registryConfig *v1alpha1.RegistryConfig) error {
+	_, shootClient, err := util.NewClientForShoot(ctx, a.client, cluster.ObjectMeta.Name, client.Options{}, extensionsconfig.RESTOptions{})
+	if err != nil {
+		return fmt.Errorf("shoot client cannot be crated: %w", err)
+	}
+
+	cacheNamespace := registryCacheNamespace(registryConfig)
+
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := shootClient.List(ctx, pvcs, client.InNamespace(cacheNamespace)); err != nil {
+		// volume statistics are optional, do not fail the reconciliation when they cannot be read
+		log.Error(err, "could not read cache volume claims from shoot")
+		pvcs.Items = nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := shootClient.List(ctx, pods, client.InNamespace(cacheNamespace)); err != nil {
+		// keep the previous condition when the pods cannot be read instead of flapping
+		log.Error(err, "could not read cache pods from shoot")
+		pods = nil
+	}
+
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := shootClient.List(ctx, statefulSets, client.InNamespace(cacheNamespace)); err != nil {
+		// keep the previous condition when the workloads cannot be read instead of flapping
+		log.Error(err, "could not read cache statefulsets from shoot")
+		statefulSets = nil
+	}
+
+	ensurerObserved := true
+	ensurer := &appsv1.DaemonSet{}
+	if err := shootClient.Get(ctx, client.ObjectKey{Namespace: cacheNamespace, Name: criEnsurerName}, ensurer); err != nil {
+		ensurer = nil
+		if !apierrors.IsNotFound(err) {
+			// keep the previous condition when the ensurer cannot be read instead of flapping
+			log.Error(err, "could not read the cri-config-ensurer daemonset from shoot")
+			ensurerObserved = false
+		}
+	}
+
+	pulledBytes, err := a.pulledBytes(ctx, ex.GetNamespace())
+	if err != nil {
+		// the byte totals are informational, do not fail the reconciliation when Prometheus
+		// is unavailable
+		log.Error(err, "could not query pulled bytes from prometheus")
+		pulledBytes = nil
+	}
+
+	status := &v1alpha1.RegistryStatus{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			Kind:       "RegistryStatus",
+		},
+		Caches: computeCacheStatuses(registryConfig, pvcs.Items, pulledBytes),
+	}
+
+	if pointer.BoolDeref(registryConfig.SuggestCaches, false) {
+		workloadPods := &corev1.PodList{}
+		if err := shootClient.List(ctx, workloadPods); err != nil {
+			// the suggestions are informational only
+			log.Error(err, "could not read shoot pods for cache suggestions")
+		} else {
+			status.SuggestedUpstreams = suggestedUpstreams(registryConfig, workloadPods.Items)
+		}
+	}
+
 	patch := client.MergeFrom(ex.DeepCopy())
-	// ex.Status.Resources = resources
+	ex.Status.ProviderStatus = &runtime.RawExtension{Object: status}
+	if pods != nil {
+		ex.Status.Conditions = v1beta1helper.MergeConditions(ex.Status.Conditions, a.healthCondition(ex.Status.Conditions, registryConfig, pods.Items))
+	}
+	if statefulSets != nil {
+		ex.Status.Conditions = v1beta1helper.MergeConditions(ex.Status.Conditions, a.readinessCondition(ex.Status.Conditions, registryConfig, statefulSets.Items))
+	}
+	if ensurerObserved {
+		ex.Status.Conditions = v1beta1helper.MergeConditions(ex.Status.Conditions, a.mirrorCondition(ex.Status.Conditions, ensurer))
+	}
 	return a.client.Status().Patch(ctx, ex, patch)
 }
+
+// computeCacheStatuses derives per-cache statistics from the cache volume claims and the byte
+// totals observed in the shoot. Caches whose volume is not yet provisioned are reported without
+// capacity; byte totals are reported as observed by the shoot Prometheus.
+func computeCacheStatuses(registryConfig *v1alpha1.RegistryConfig, pvcs []corev1.PersistentVolumeClaim, pulledBytes map[string]int64) []v1alpha1.RegistryCacheStatus {
+	capacityByUpstream := map[string]*resource.Quantity{}
+	for i := range pvcs {
+		upstream, ok := pvcs[i].Labels[registryCacheServiceUpstreamLabel]
+		if !ok {
+			continue
+		}
+		if capacity, ok := pvcs[i].Status.Capacity[corev1.ResourceStorage]; ok {
+			capacityByUpstream[upstream] = &capacity
+		}
+	}
+
+	statuses := make([]v1alpha1.RegistryCacheStatus, 0, len(registryConfig.Caches))
+	for _, cache := range registryConfig.Caches {
+		status := v1alpha1.RegistryCacheStatus{
+			Upstream:       cache.Upstream,
+			VolumeCapacity: capacityByUpstream[cache.Upstream],
+		}
+		if bytes, ok := pulledBytes[cache.Upstream]; ok {
+			status.PulledBytes = resource.NewQuantity(bytes, resource.BinarySI)
+		}
+		status.ResolvedConfig = cache.DeepCopy()
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
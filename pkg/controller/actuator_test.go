@@ -0,0 +1,288 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	resourcesv1alpha1 "github.com/gardener/gardener/pkg/apis/resources/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha1"
+)
+
+// flakyClient fails the first failures Get calls with the given error and delegates afterwards.
+type flakyClient struct {
+	client.Client
+
+	err      error
+	failures int
+	calls    int
+}
+
+func (f *flakyClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	f.calls++
+	if f.calls <= f.failures {
+		return f.err
+	}
+	return f.Client.Get(ctx, key, obj, opts...)
+}
+
+// TODO: migrate to ginkgo test
+func Test_actuator_createManagedResourcesRetriesTransientErrors(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(resourcesv1alpha1.AddToScheme(scheme))
+
+	tests := []struct {
+		name     string
+		err      error
+		failures int
+		wantErr  bool
+	}{
+		{
+			name:     "transient errors are retried",
+			err:      apierrors.NewServiceUnavailable("apiserver overloaded"),
+			failures: 2,
+			wantErr:  false,
+		},
+		{
+			name:     "permanent errors are returned immediately",
+			err:      apierrors.NewBadRequest("malformed object"),
+			failures: 1,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			c := &flakyClient{
+				Client:   fake.NewClientBuilder().WithScheme(scheme).Build(),
+				err:      tt.err,
+				failures: tt.failures,
+			}
+			a := &actuator{client: c}
+
+			err := a.createManagedResources(context.Background(), "test", "shoot--foo--bar", map[string][]byte{}, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("actuator.createManagedResources() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && c.calls != tt.failures {
+				t.Errorf("expected no retries for permanent errors, got %d calls", c.calls)
+			}
+		})
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_computeCacheStatuses(t *testing.T) {
+	capacity := resource.MustParse("10Gi")
+	registryConfig := &v1alpha1.RegistryConfig{
+		Caches: []v1alpha1.RegistryCache{
+			{Upstream: "docker.io"},
+			{Upstream: "ghcr.io"},
+		},
+	}
+	// the actuator computes the statuses from the defaulted configuration
+	for i := range registryConfig.Caches {
+		v1alpha1.SetDefaults_RegistryCache(&registryConfig.Caches[i])
+	}
+	pvcs := []corev1.PersistentVolumeClaim{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{registryCacheServiceUpstreamLabel: "docker.io"},
+			},
+			Status: corev1.PersistentVolumeClaimStatus{
+				Capacity: corev1.ResourceList{corev1.ResourceStorage: capacity},
+			},
+		},
+	}
+
+	statuses := computeCacheStatuses(registryConfig, pvcs, map[string]int64{"docker.io": 1024})
+
+	if len(statuses) != 2 {
+		t.Fatalf("expected statuses for all configured caches, got %v", statuses)
+	}
+	if statuses[0].Upstream != "docker.io" || statuses[0].VolumeCapacity == nil || statuses[0].VolumeCapacity.Cmp(capacity) != 0 {
+		t.Errorf("expected docker.io status with capacity %s, got %+v", capacity.String(), statuses[0])
+	}
+	if statuses[0].PulledBytes == nil || statuses[0].PulledBytes.Value() != 1024 {
+		t.Errorf("expected docker.io status with 1024 pulled bytes, got %+v", statuses[0])
+	}
+	if statuses[1].Upstream != "ghcr.io" || statuses[1].VolumeCapacity != nil || statuses[1].PulledBytes != nil {
+		t.Errorf("expected ghcr.io status without capacity and pulled bytes, got %+v", statuses[1])
+	}
+	resolved := statuses[0].ResolvedConfig
+	if resolved == nil {
+		t.Fatalf("expected the docker.io status to carry the resolved configuration, got %+v", statuses[0])
+	}
+	if resolved.Size == nil || resolved.Size.Cmp(capacity) != 0 || resolved.GarbageCollectionEnabled == nil || !*resolved.GarbageCollectionEnabled {
+		t.Errorf("expected the resolved configuration to reflect the applied defaults, got %+v", resolved)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_sharedObjects(t *testing.T) {
+	objects := sharedObjects(registryCacheNamespaceName, nil)
+
+	var serviceAccount *corev1.ServiceAccount
+	for _, obj := range objects {
+		if sa, ok := obj.(*corev1.ServiceAccount); ok {
+			serviceAccount = sa
+		}
+	}
+
+	if serviceAccount == nil {
+		t.Fatalf("expected a ServiceAccount in the shared objects, got %v", objects)
+	}
+	if serviceAccount.Name != registryCacheServiceAccountName || serviceAccount.Namespace != registryCacheNamespaceName {
+		t.Errorf("expected ServiceAccount %s/%s, got %s/%s", registryCacheNamespaceName, registryCacheServiceAccountName, serviceAccount.Namespace, serviceAccount.Name)
+	}
+	if serviceAccount.AutomountServiceAccountToken == nil || *serviceAccount.AutomountServiceAccountToken {
+		t.Errorf("expected automounting the service account token to be disabled, got %v", serviceAccount.AutomountServiceAccountToken)
+	}
+
+	for _, obj := range objects {
+		if _, ok := obj.(*corev1.ResourceQuota); ok {
+			t.Errorf("expected no ResourceQuota without a configured quota, got %v", obj)
+		}
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_sharedObjects_Quota(t *testing.T) {
+	quota := resource.MustParse("100Gi")
+	objects := sharedObjects("dedicated-caches", &quota)
+
+	var (
+		namespace     *corev1.Namespace
+		resourceQuota *corev1.ResourceQuota
+	)
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *corev1.Namespace:
+			namespace = o
+		case *corev1.ResourceQuota:
+			resourceQuota = o
+		}
+	}
+
+	if namespace == nil || namespace.Name != "dedicated-caches" {
+		t.Errorf("expected the Namespace %q in the shared objects, got %v", "dedicated-caches", namespace)
+	}
+	if resourceQuota == nil {
+		t.Fatalf("expected a ResourceQuota in the shared objects, got %v", objects)
+	}
+	if resourceQuota.Namespace != "dedicated-caches" {
+		t.Errorf("expected the ResourceQuota in namespace %q, got %q", "dedicated-caches", resourceQuota.Namespace)
+	}
+	hard, ok := resourceQuota.Spec.Hard[corev1.ResourceRequestsStorage]
+	if !ok || hard.Cmp(quota) != 0 {
+		t.Errorf("expected a hard limit of %s on requested storage, got %v", quota.String(), resourceQuota.Spec.Hard)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_deferDisruptiveChanges(t *testing.T) {
+	inWindow := time.Date(2023, 1, 1, 22, 30, 0, 0, time.UTC)
+	outsideWindow := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cache := v1alpha1.RegistryCache{}
+	if deferred, err := deferDisruptiveChanges(cache, outsideWindow); err != nil || deferred {
+		t.Errorf("expected no deferral without a maintenance window, got %v, %v", deferred, err)
+	}
+
+	cache.MaintenanceWindow = &v1alpha1.MaintenanceWindow{Begin: "220000+0000", End: "230000+0000"}
+	if deferred, err := deferDisruptiveChanges(cache, inWindow); err != nil || deferred {
+		t.Errorf("expected no deferral inside the maintenance window, got %v, %v", deferred, err)
+	}
+	if deferred, err := deferDisruptiveChanges(cache, outsideWindow); err != nil || !deferred {
+		t.Errorf("expected deferral outside the maintenance window, got %v, %v", deferred, err)
+	}
+
+	cache.MaintenanceWindow = &v1alpha1.MaintenanceWindow{Begin: "invalid", End: "230000+0000"}
+	if _, err := deferDisruptiveChanges(cache, inWindow); err == nil {
+		t.Error("expected an error for an invalid maintenance window")
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_applyDefaultCaches(t *testing.T) {
+	defaults := &config.DefaultCachesConfig{
+		ShootLabel: "registry-cache.extensions.gardener.cloud/default-caches",
+		Upstreams:  []string{"docker.io", "quay.io"},
+	}
+
+	registryConfig := &v1alpha1.RegistryConfig{}
+	applyDefaultCaches(defaults, registryConfig, map[string]string{})
+	if len(registryConfig.Caches) != 0 {
+		t.Errorf("expected no default caches for an unlabelled shoot, got %v", registryConfig.Caches)
+	}
+
+	registryConfig = &v1alpha1.RegistryConfig{}
+	applyDefaultCaches(defaults, registryConfig, map[string]string{defaults.ShootLabel: "true"})
+	if len(registryConfig.Caches) != 2 || registryConfig.Caches[0].Upstream != "docker.io" || registryConfig.Caches[1].Upstream != "quay.io" {
+		t.Fatalf("expected default caches for a labelled shoot, got %v", registryConfig.Caches)
+	}
+	if registryConfig.Caches[0].Size == nil || registryConfig.Caches[0].GarbageCollectionEnabled == nil {
+		t.Errorf("expected default caches with defaulted settings, got %+v", registryConfig.Caches[0])
+	}
+
+	registryConfig = &v1alpha1.RegistryConfig{
+		Caches: []v1alpha1.RegistryCache{{Upstream: "docker.io", Size: resourceQuantityPtr("42Gi")}},
+	}
+	applyDefaultCaches(defaults, registryConfig, map[string]string{defaults.ShootLabel: "true"})
+	if len(registryConfig.Caches) != 2 || registryConfig.Caches[0].Size.String() != "42Gi" {
+		t.Errorf("expected the explicitly configured cache to take precedence, got %v", registryConfig.Caches)
+	}
+
+	defaults.ShootLabelValue = pointer.String("enabled")
+	registryConfig = &v1alpha1.RegistryConfig{}
+	applyDefaultCaches(defaults, registryConfig, map[string]string{defaults.ShootLabel: "true"})
+	if len(registryConfig.Caches) != 0 {
+		t.Errorf("expected no default caches for a non-matching label value, got %v", registryConfig.Caches)
+	}
+}
+
+func resourceQuantityPtr(value string) *resource.Quantity {
+	quantity := resource.MustParse(value)
+	return &quantity
+}
+
+// TODO: migrate to ginkgo test
+func Test_resolveEvictionTarget(t *testing.T) {
+	registryConfig := &v1alpha1.RegistryConfig{
+		Caches: []v1alpha1.RegistryCache{{Upstream: "docker.io"}},
+	}
+
+	upstream, repository, err := resolveEvictionTarget("docker.io/library/nginx", registryConfig)
+	if err != nil {
+		t.Fatalf("resolveEvictionTarget() error = %v", err)
+	}
+	if upstream != "docker.io" || repository != "library/nginx" {
+		t.Errorf("resolveEvictionTarget() = %q, %q, want docker.io, library/nginx", upstream, repository)
+	}
+
+	if upstream, repository, err = resolveEvictionTarget("", registryConfig); err != nil || upstream != "" || repository != "" {
+		t.Errorf("expected no eviction without an annotation, got %q, %q, %v", upstream, repository, err)
+	}
+
+	if _, _, err := resolveEvictionTarget("quay.io/library/nginx", registryConfig); err == nil {
+		t.Error("expected an error for an upstream without a cache")
+	}
+	if _, _, err := resolveEvictionTarget("docker.io/library/$(reboot)", registryConfig); err == nil {
+		t.Error("expected an error for a repository that is no plain repository name")
+	}
+	if _, _, err := resolveEvictionTarget("docker.io", registryConfig); err == nil {
+		t.Error("expected an error for a target without a repository")
+	}
+}
@@ -15,9 +15,14 @@
 package controller
 
 import (
+	"time"
+
 	"github.com/gardener/gardener/extensions/pkg/controller/extension"
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
 
 	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
 )
@@ -56,7 +61,7 @@ func AddToManager(mgr manager.Manager) error {
 func AddToManagerWithOptions(mgr manager.Manager, opts AddOptions) error {
 	return extension.Add(mgr, extension.AddArgs{
 		Actuator:          NewActuator(opts.Config),
-		ControllerOptions: opts.ControllerOptions,
+		ControllerOptions: controllerOptions(opts),
 		Name:              ControllerName,
 		FinalizerSuffix:   FinalizerSuffix,
 		Resync:            0,
@@ -64,3 +69,50 @@ func AddToManagerWithOptions(mgr manager.Manager, opts AddOptions) error {
 		Type:              Type,
 	})
 }
+
+// controllerOptions applies the controller tuning from the service configuration to the given
+// controller options. Values set explicitly on the options (e.g. via command line flags) take
+// precedence over the configuration.
+func controllerOptions(opts AddOptions) controller.Options {
+	options := opts.ControllerOptions
+
+	if opts.Config.RateLimiter != nil && options.RateLimiter == nil {
+		options.RateLimiter = reconcileRateLimiter(opts.Config.RateLimiter)
+	}
+	if opts.Config.MaxConcurrentReconciles != nil && options.MaxConcurrentReconciles == 0 {
+		options.MaxConcurrentReconciles = int(*opts.Config.MaxConcurrentReconciles)
+	}
+
+	return options
+}
+
+// reconcileRateLimiter builds the workqueue rate limiter of the extension controller from the
+// given configuration. It mirrors workqueue.DefaultControllerRateLimiter, with the per-item
+// exponential backoff and the overall token bucket made configurable, so that reconciles under
+// heavy cluster churn cannot overwhelm the seed.
+func reconcileRateLimiter(config *config.RateLimiterConfig) ratelimiter.RateLimiter {
+	var (
+		baseDelay = 5 * time.Millisecond
+		maxDelay  = 1000 * time.Second
+		qps       = float32(10)
+		burst     = 100
+	)
+
+	if config.BaseDelay != nil {
+		baseDelay = config.BaseDelay.Duration
+	}
+	if config.MaxDelay != nil {
+		maxDelay = config.MaxDelay.Duration
+	}
+	if config.QPS != nil {
+		qps = *config.QPS
+	}
+	if config.Burst != nil {
+		burst = int(*config.Burst)
+	}
+
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(qps), burst)},
+	)
+}
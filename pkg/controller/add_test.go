@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/pointer"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
+)
+
+// TODO: migrate to ginkgo test
+func Test_controllerOptions(t *testing.T) {
+	opts := AddOptions{
+		Config: config.Configuration{
+			MaxConcurrentReconciles: pointer.Int32(5),
+			RateLimiter:             &config.RateLimiterConfig{},
+		},
+	}
+
+	options := controllerOptions(opts)
+	if options.MaxConcurrentReconciles != 5 {
+		t.Errorf("MaxConcurrentReconciles = %d, want 5", options.MaxConcurrentReconciles)
+	}
+	if options.RateLimiter == nil {
+		t.Error("expected the configured rate limiter to be set")
+	}
+
+	// values set explicitly on the controller options win over the configuration
+	opts.ControllerOptions.MaxConcurrentReconciles = 2
+	if got := controllerOptions(opts).MaxConcurrentReconciles; got != 2 {
+		t.Errorf("MaxConcurrentReconciles = %d, want the explicit value 2", got)
+	}
+
+	// without configuration the controller-runtime defaults stay untouched
+	if got := controllerOptions(AddOptions{}).MaxConcurrentReconciles; got != 0 {
+		t.Errorf("MaxConcurrentReconciles = %d, want 0", got)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_reconcileRateLimiter(t *testing.T) {
+	rl := reconcileRateLimiter(&config.RateLimiterConfig{
+		BaseDelay: &metav1.Duration{Duration: time.Second},
+		MaxDelay:  &metav1.Duration{Duration: 4 * time.Second},
+		QPS:       pointer.Float32(1000),
+		Burst:     pointer.Int32(1000),
+	})
+
+	// failing items back off exponentially from baseDelay, capped at maxDelay
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		if got := rl.When("item"); got != want {
+			t.Errorf("retry %d delayed by %s, want %s", i+1, got, want)
+		}
+	}
+	if got := rl.NumRequeues("item"); got != 4 {
+		t.Errorf("NumRequeues() = %d, want 4", got)
+	}
+
+	rl.Forget("item")
+	if got := rl.When("item"); got != time.Second {
+		t.Errorf("retry after Forget() delayed by %s, want %s", got, time.Second)
+	}
+
+	// a workqueue built on the limiter holds rate-limited items back until the backoff elapsed
+	queue := workqueue.NewRateLimitingQueue(rl)
+	defer queue.ShutDown()
+
+	queue.AddRateLimited("other")
+	if got := queue.Len(); got != 0 {
+		t.Errorf("queue length = %d, want the item to be held back by the rate limiter", got)
+	}
+}
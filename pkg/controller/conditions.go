@@ -0,0 +1,157 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	v1beta1helper "github.com/gardener/gardener/pkg/apis/core/v1beta1/helper"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha1"
+)
+
+const (
+	// RegistryCacheHealthyConditionType is the Extension condition reporting whether the cache
+	// pods run without excessive restarts.
+	RegistryCacheHealthyConditionType gardencorev1beta1.ConditionType = "RegistryCacheHealthy"
+
+	// RegistryCachesReadyConditionType is the Extension condition aggregating the readiness of
+	// all cache workloads into a single value, so that external (non-Prometheus) health systems
+	// only need to watch one field.
+	RegistryCachesReadyConditionType gardencorev1beta1.ConditionType = "RegistryCachesReady"
+
+	// RegistryMirrorConfiguredConditionType is the Extension condition reporting whether the
+	// containerd mirror configuration has been rolled out to the shoot nodes, so that a
+	// not-applied configuration (which silently disables caching) does not go unnoticed.
+	RegistryMirrorConfiguredConditionType gardencorev1beta1.ConditionType = "RegistryMirrorConfigured"
+
+	// defaultDegradedRestartThreshold is the restart count after which a cache is reported as
+	// degraded unless configured otherwise.
+	defaultDegradedRestartThreshold int32 = 5
+)
+
+func (a *actuator) degradedRestartThreshold() int32 {
+	if a.config.DegradedRestartThreshold != nil {
+		return *a.config.DegradedRestartThreshold
+	}
+
+	return defaultDegradedRestartThreshold
+}
+
+// healthCondition computes the RegistryCacheHealthy condition from the restart counts of the
+// cache pods observed in the shoot, so that crashloops surface to users who do not watch the
+// pods directly.
+func (a *actuator) healthCondition(conditions []gardencorev1beta1.Condition, registryConfig *v1alpha1.RegistryConfig, pods []corev1.Pod) gardencorev1beta1.Condition {
+	condition := v1beta1helper.GetOrInitCondition(conditions, RegistryCacheHealthyConditionType)
+
+	if degraded := degradedCaches(registryConfig, pods, a.degradedRestartThreshold()); len(degraded) > 0 {
+		return v1beta1helper.UpdatedCondition(condition, gardencorev1beta1.ConditionFalse, "CachePodsRestarting",
+			fmt.Sprintf("The registry cache pods for upstream(s) %s restarted more than %d times.", strings.Join(degraded, ", "), a.degradedRestartThreshold()))
+	}
+
+	return v1beta1helper.UpdatedCondition(condition, gardencorev1beta1.ConditionTrue, "CachePodsRunning", "All registry cache pods are below the restart threshold.")
+}
+
+// readinessCondition computes the RegistryCachesReady condition from the cache StatefulSets
+// observed in the shoot.
+func (a *actuator) readinessCondition(conditions []gardencorev1beta1.Condition, registryConfig *v1alpha1.RegistryConfig, statefulSets []appsv1.StatefulSet) gardencorev1beta1.Condition {
+	condition := v1beta1helper.GetOrInitCondition(conditions, RegistryCachesReadyConditionType)
+
+	if unready := unreadyCaches(registryConfig, statefulSets); len(unready) > 0 {
+		return v1beta1helper.UpdatedCondition(condition, gardencorev1beta1.ConditionFalse, "CachesNotReady",
+			fmt.Sprintf("%d of %d registry caches are ready; waiting for upstream(s) %s.", len(registryConfig.Caches)-len(unready), len(registryConfig.Caches), strings.Join(unready, ", ")))
+	}
+
+	return v1beta1helper.UpdatedCondition(condition, gardencorev1beta1.ConditionTrue, "CachesReady",
+		fmt.Sprintf("All %d registry caches are ready.", len(registryConfig.Caches)))
+}
+
+// mirrorCondition computes the RegistryMirrorConfigured condition from the cri-config-ensurer
+// DaemonSet observed in the shoot. The ensurer pods apply the mirror drop-ins on their node and
+// restart containerd, so the configuration only counts as picked up once the current pod
+// generation runs on every node.
+func (a *actuator) mirrorCondition(conditions []gardencorev1beta1.Condition, daemonSet *appsv1.DaemonSet) gardencorev1beta1.Condition {
+	condition := v1beta1helper.GetOrInitCondition(conditions, RegistryMirrorConfiguredConditionType)
+
+	if daemonSet == nil {
+		return v1beta1helper.UpdatedCondition(condition, gardencorev1beta1.ConditionFalse, "EnsurerNotFound",
+			"The cri-config-ensurer DaemonSet has not been observed in the shoot yet.")
+	}
+
+	status := daemonSet.Status
+	if status.DesiredNumberScheduled == 0 || daemonSet.Generation != status.ObservedGeneration ||
+		status.UpdatedNumberScheduled < status.DesiredNumberScheduled || status.NumberAvailable < status.DesiredNumberScheduled {
+		return v1beta1helper.UpdatedCondition(condition, gardencorev1beta1.ConditionFalse, "EnsurerRollingOut",
+			fmt.Sprintf("The containerd mirror configuration is applied on %d of %d nodes.", status.NumberAvailable, status.DesiredNumberScheduled))
+	}
+
+	return v1beta1helper.UpdatedCondition(condition, gardencorev1beta1.ConditionTrue, "MirrorConfigured",
+		fmt.Sprintf("The containerd mirror configuration is applied on all %d nodes.", status.DesiredNumberScheduled))
+}
+
+// unreadyCaches returns the upstreams of the configured caches whose StatefulSet has no ready
+// replica. Caches whose StatefulSet has not been observed at all count as unready, too.
+func unreadyCaches(registryConfig *v1alpha1.RegistryConfig, statefulSets []appsv1.StatefulSet) []string {
+	readyByUpstream := map[string]bool{}
+	for _, statefulSet := range statefulSets {
+		upstream, ok := statefulSet.Labels[registryCacheServiceUpstreamLabel]
+		if !ok {
+			continue
+		}
+		readyByUpstream[upstream] = statefulSet.Status.ReadyReplicas > 0
+	}
+
+	var unready []string
+	for _, cache := range registryConfig.Caches {
+		if !readyByUpstream[cache.Upstream] {
+			unready = append(unready, cache.Upstream)
+		}
+	}
+	sort.Strings(unready)
+
+	return unready
+}
+
+// degradedCaches returns the upstreams of the configured caches whose pods restarted more often
+// than the given threshold. Pods are matched to their cache via the upstream label.
+func degradedCaches(registryConfig *v1alpha1.RegistryConfig, pods []corev1.Pod, threshold int32) []string {
+	restartsByUpstream := map[string]int32{}
+	for _, pod := range pods {
+		upstream, ok := pod.Labels[registryCacheServiceUpstreamLabel]
+		if !ok {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.RestartCount > restartsByUpstream[upstream] {
+				restartsByUpstream[upstream] = status.RestartCount
+			}
+		}
+	}
+
+	var degraded []string
+	for _, cache := range registryConfig.Caches {
+		if restartsByUpstream[cache.Upstream] > threshold {
+			degraded = append(degraded, cache.Upstream)
+		}
+	}
+	sort.Strings(degraded)
+
+	return degraded
+}
@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha1"
+)
+
+func cachePod(upstreamLabel string, restartCount int32) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				registryCacheServiceUpstreamLabel: upstreamLabel,
+			},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				RestartCount: restartCount,
+			}},
+		},
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_degradedCaches(t *testing.T) {
+	registryConfig := &v1alpha1.RegistryConfig{
+		Caches: []v1alpha1.RegistryCache{
+			{Upstream: "docker.io"},
+			{Upstream: "ghcr.io"},
+			{Upstream: "quay.io"},
+		},
+	}
+	pods := []corev1.Pod{
+		cachePod("docker.io", 7),
+		cachePod("ghcr.io", 2),
+		cachePod("quay.io", 6),
+	}
+
+	got := degradedCaches(registryConfig, pods, 5)
+	if want := []string{"docker.io", "quay.io"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("degradedCaches() = %v, want %v", got, want)
+	}
+
+	if got := degradedCaches(registryConfig, pods, 10); got != nil {
+		t.Errorf("degradedCaches() = %v, want none", got)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_actuator_healthCondition(t *testing.T) {
+	a := &actuator{config: config.Configuration{DegradedRestartThreshold: pointer.Int32(3)}}
+	registryConfig := &v1alpha1.RegistryConfig{
+		Caches: []v1alpha1.RegistryCache{{Upstream: "docker.io"}},
+	}
+
+	condition := a.healthCondition(nil, registryConfig, []corev1.Pod{cachePod("docker.io", 2)})
+	if condition.Type != RegistryCacheHealthyConditionType {
+		t.Errorf("condition type = %q, want %q", condition.Type, RegistryCacheHealthyConditionType)
+	}
+	if condition.Status != gardencorev1beta1.ConditionTrue {
+		t.Errorf("condition status = %q, want %q", condition.Status, gardencorev1beta1.ConditionTrue)
+	}
+
+	condition = a.healthCondition(nil, registryConfig, []corev1.Pod{cachePod("docker.io", 4)})
+	if condition.Status != gardencorev1beta1.ConditionFalse {
+		t.Errorf("condition status = %q, want %q", condition.Status, gardencorev1beta1.ConditionFalse)
+	}
+	if condition.Reason != "CachePodsRestarting" {
+		t.Errorf("condition reason = %q, want CachePodsRestarting", condition.Reason)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_actuator_mirrorCondition(t *testing.T) {
+	a := &actuator{config: config.Configuration{}}
+
+	ensurer := func(desired, updated, available int32) *appsv1.DaemonSet {
+		return &appsv1.DaemonSet{
+			Status: appsv1.DaemonSetStatus{
+				DesiredNumberScheduled: desired,
+				UpdatedNumberScheduled: updated,
+				NumberAvailable:        available,
+			},
+		}
+	}
+
+	condition := a.mirrorCondition(nil, nil)
+	if condition.Type != RegistryMirrorConfiguredConditionType {
+		t.Errorf("condition type = %q, want %q", condition.Type, RegistryMirrorConfiguredConditionType)
+	}
+	if condition.Status != gardencorev1beta1.ConditionFalse || condition.Reason != "EnsurerNotFound" {
+		t.Errorf("condition = %q/%q, want False/EnsurerNotFound", condition.Status, condition.Reason)
+	}
+
+	condition = a.mirrorCondition(nil, ensurer(3, 3, 2))
+	if condition.Status != gardencorev1beta1.ConditionFalse || condition.Reason != "EnsurerRollingOut" {
+		t.Errorf("condition = %q/%q, want False/EnsurerRollingOut", condition.Status, condition.Reason)
+	}
+	if want := "The containerd mirror configuration is applied on 2 of 3 nodes."; condition.Message != want {
+		t.Errorf("condition message = %q, want %q", condition.Message, want)
+	}
+
+	// an updated spec which the controller has not observed yet must not report success
+	stale := ensurer(3, 3, 3)
+	stale.Generation = 2
+	stale.Status.ObservedGeneration = 1
+	if condition = a.mirrorCondition(nil, stale); condition.Status != gardencorev1beta1.ConditionFalse {
+		t.Errorf("condition status = %q, want %q for a stale observed generation", condition.Status, gardencorev1beta1.ConditionFalse)
+	}
+
+	condition = a.mirrorCondition(nil, ensurer(3, 3, 3))
+	if condition.Status != gardencorev1beta1.ConditionTrue || condition.Reason != "MirrorConfigured" {
+		t.Errorf("condition = %q/%q, want True/MirrorConfigured", condition.Status, condition.Reason)
+	}
+}
+
+func cacheStatefulSet(upstreamLabel string, readyReplicas int32) appsv1.StatefulSet {
+	return appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				registryCacheServiceUpstreamLabel: upstreamLabel,
+			},
+		},
+		Status: appsv1.StatefulSetStatus{
+			ReadyReplicas: readyReplicas,
+		},
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_unreadyCaches(t *testing.T) {
+	registryConfig := &v1alpha1.RegistryConfig{
+		Caches: []v1alpha1.RegistryCache{
+			{Upstream: "docker.io"},
+			{Upstream: "ghcr.io"},
+			{Upstream: "quay.io"},
+		},
+	}
+	statefulSets := []appsv1.StatefulSet{
+		cacheStatefulSet("docker.io", 1),
+		cacheStatefulSet("quay.io", 0),
+		// the ghcr.io statefulset has not been observed at all
+	}
+
+	got := unreadyCaches(registryConfig, statefulSets)
+	want := []string{"ghcr.io", "quay.io"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unreadyCaches() = %v, want %v", got, want)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_actuator_readinessCondition(t *testing.T) {
+	a := &actuator{config: config.Configuration{}}
+	registryConfig := &v1alpha1.RegistryConfig{
+		Caches: []v1alpha1.RegistryCache{
+			{Upstream: "docker.io"},
+			{Upstream: "ghcr.io"},
+		},
+	}
+
+	condition := a.readinessCondition(nil, registryConfig, []appsv1.StatefulSet{
+		cacheStatefulSet("docker.io", 1),
+		cacheStatefulSet("ghcr.io", 0),
+	})
+	if condition.Type != RegistryCachesReadyConditionType {
+		t.Errorf("condition type = %q, want %q", condition.Type, RegistryCachesReadyConditionType)
+	}
+	if condition.Status != gardencorev1beta1.ConditionFalse {
+		t.Errorf("condition status = %q, want %q", condition.Status, gardencorev1beta1.ConditionFalse)
+	}
+	if want := "1 of 2 registry caches are ready; waiting for upstream(s) ghcr.io."; condition.Message != want {
+		t.Errorf("condition message = %q, want %q", condition.Message, want)
+	}
+
+	condition = a.readinessCondition(nil, registryConfig, []appsv1.StatefulSet{
+		cacheStatefulSet("docker.io", 1),
+		cacheStatefulSet("ghcr.io", 1),
+	})
+	if condition.Status != gardencorev1beta1.ConditionTrue {
+		t.Errorf("condition status = %q, want %q", condition.Status, gardencorev1beta1.ConditionTrue)
+	}
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"bytes"
 	"fmt"
+	"sort"
 	"text/template"
 
 	"github.com/gardener/gardener/pkg/utils/imagevector"
@@ -22,6 +23,16 @@ type criEnsurer struct {
 	CRIEnsurerImage *imagevector.Image
 
 	ReferencedServices *corev1.ServiceList
+
+	// DisableMirrorInjection renders the containerd drop-in without any mirror entries while
+	// keeping the caches deployed. It serves as a kill switch for incident response; flipping
+	// it back re-renders the mirror entries.
+	DisableMirrorInjection bool
+
+	// MirrorConfigFormat forces the format the mirror configuration is applied in on the
+	// nodes ("inline" or "hosts"). If empty, every node picks the format matching its
+	// containerd version.
+	MirrorConfigFormat string
 }
 
 const (
@@ -32,8 +43,13 @@ const (
 set -euo pipefail
 
 CONTAINERD_IMPORTS_DIR="/etc/containerd/conf.d"
+CONTAINERD_CERTS_DIR="/etc/containerd/certs.d"
 CONFIG_INPUT_FILE="$1"
+CONFIG_PATH_INPUT_FILE="$2"
+HOSTS_INPUT_FILE="$3"
+FORMAT="$4"
 TARGET_FILE="/host$CONTAINERD_IMPORTS_DIR/$(basename "$CONFIG_INPUT_FILE")"
+MANAGED_HOSTS_FILE="/host$CONTAINERD_CERTS_DIR/.managed-by-registry-cache"
 
 if ! grep -F '/etc/containerd/conf.d/*.toml' /host/etc/containerd/config.toml >/dev/null ; then
 	# https://github.com/gardener/gardener/blob/v1.51.0/docs/usage/custom-containerd-config.md
@@ -41,17 +57,55 @@ if ! grep -F '/etc/containerd/conf.d/*.toml' /host/etc/containerd/config.toml >/
 	exit 1
 fi
 
-if [ ! -e "$CONFIG_INPUT_FILE" ]; then
-	echo "ERROR: Config input file $CONFIG_INPUT_FILE could not be found, exiting."
+if [ ! -e "$CONFIG_INPUT_FILE" ] || [ ! -e "$CONFIG_PATH_INPUT_FILE" ] || [ ! -e "$HOSTS_INPUT_FILE" ]; then
+	echo "ERROR: Config input files could not be found, exiting."
 	exit 1
 fi
 
+if [ "$FORMAT" = "auto" ]; then
+	# hosts.toml drop-ins are supported since containerd 1.5
+	CONTAINERD_VERSION="$(chroot /host containerd --version | awk '{print $3}' | sed 's/^v//')"
+	if [ "$(printf '%s\n' 1.5.0 "$CONTAINERD_VERSION" | sort -V | head -n1)" = "1.5.0" ]; then
+		FORMAT="hosts"
+	else
+		FORMAT="inline"
+	fi
+	echo "detected containerd $CONTAINERD_VERSION, using the $FORMAT mirror configuration format"
+fi
+
+apply_hosts() {
+	mkdir -p "/host$CONTAINERD_CERTS_DIR"
+	: > "$MANAGED_HOSTS_FILE.new"
+	awk -v certs="/host$CONTAINERD_CERTS_DIR" -v managed="$MANAGED_HOSTS_FILE.new" '
+		/^# host: / { close(out); host=$3; print host >> managed; system("mkdir -p \"" certs "/" host "\""); out=certs "/" host "/hosts.toml"; next }
+		out { print > out }
+	' "$HOSTS_INPUT_FILE"
+
+	# drop the hosts.toml of hosts managed earlier but no longer configured; hosts.toml
+	# changes are picked up by containerd without a restart
+	if [ -e "$MANAGED_HOSTS_FILE" ]; then
+		while read -r host; do
+			if ! grep -Fxq "$host" "$MANAGED_HOSTS_FILE.new"; then
+				rm -rf "/host$CONTAINERD_CERTS_DIR/${host:?}"
+			fi
+		done < "$MANAGED_HOSTS_FILE"
+	fi
+	mv "$MANAGED_HOSTS_FILE.new" "$MANAGED_HOSTS_FILE"
+}
+
 mkdir -p "/host$CONTAINERD_IMPORTS_DIR"
 
 while true; do
-	if ! cmp -s "$CONFIG_INPUT_FILE" "$TARGET_FILE" ; then
+	if [ "$FORMAT" = "hosts" ]; then
+		SOURCE_FILE="$CONFIG_PATH_INPUT_FILE"
+		apply_hosts
+	else
+		SOURCE_FILE="$CONFIG_INPUT_FILE"
+	fi
+
+	if ! cmp -s "$SOURCE_FILE" "$TARGET_FILE" ; then
 		echo "applying registry mirrors"
-		cp -f "$CONFIG_INPUT_FILE" "$TARGET_FILE"
+		cp -f "$SOURCE_FILE" "$TARGET_FILE"
 
 		echo "restarting containerd"
 		chroot /host systemctl restart containerd.service
@@ -61,17 +115,41 @@ while true; do
 	fi
 	sleep 60
 done
+`
+
+	// configPathToml is the config.toml drop-in applied in the "hosts" format. It only points
+	// containerd at the certs.d directory; the mirror entries themselves live in the per-host
+	// hosts.toml drop-ins.
+	configPathToml = `# governed by gardener-extension-registry-cache, do not edit
+[plugins."io.containerd.grpc.v1.cri".registry]
+  config_path = "/etc/containerd/certs.d"
 `
 )
 
-var configTemplate *template.Template
+var (
+	configTemplate *template.Template
+	hostsTemplate  *template.Template
+)
 
 func init() {
 	configTemplate = template.Must(template.New("").
 		Parse(`# governed by gardener-extension-registry-cache, do not edit
 {{ range $mirror := . -}}
 [plugins."io.containerd.grpc.v1.cri".registry.mirrors."{{ $mirror.Host }}"]
-  endpoint = ["{{ $mirror.Endpoint }}"]
+  endpoint = ["{{ $mirror.Endpoint }}"{{ if $mirror.Fallback }}, "{{ $mirror.Fallback }}"{{ end }}]
+{{ end -}}
+`))
+
+	// the "# host:" lines separate the per-host hosts.toml files; the reconcile script splits
+	// on them when unpacking the file into the certs.d directory
+	hostsTemplate = template.Must(template.New("").
+		Parse(`{{ range $mirror := . -}}
+# host: {{ $mirror.Host }}
+# governed by gardener-extension-registry-cache, do not edit
+server = "{{ if $mirror.Fallback }}{{ $mirror.Fallback }}{{ else }}{{ $mirror.Endpoint }}{{ end }}"
+
+[host."{{ $mirror.Endpoint }}"]
+  capabilities = ["pull", "resolve"]
 {{ end -}}
 `))
 }
@@ -87,13 +165,24 @@ func (c *criEnsurer) Ensure() ([]client.Object, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable to template toml: %w", err)
 	}
+	hosts, err := c.configHosts()
+	if err != nil {
+		return nil, fmt.Errorf("unable to template hosts toml: %w", err)
+	}
 
 	const (
 		reconcileScriptKey = "reconcile.sh"
 		configTomlKey      = "70-extension-registry-cache.toml"
+		configPathTomlKey  = "70-extension-registry-cache-config-path.toml"
+		hostsTomlKey       = "hosts.toml.d"
 		workMountPath      = "/work"
 	)
 
+	format := c.MirrorConfigFormat
+	if format == "" {
+		format = "auto"
+	}
+
 	configMap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      criEnsurerName,
@@ -103,6 +192,8 @@ func (c *criEnsurer) Ensure() ([]client.Object, error) {
 		Data: map[string]string{
 			reconcileScriptKey: reconcileScript,
 			configTomlKey:      toml,
+			configPathTomlKey:  configPathToml,
+			hostsTomlKey:       hosts,
 		},
 	}
 	utilruntime.Must(kubernetes.MakeUnique(configMap))
@@ -110,7 +201,7 @@ func (c *criEnsurer) Ensure() ([]client.Object, error) {
 	daemonSet := &appsv1.DaemonSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      criEnsurerName,
-			Namespace: registryCacheNamespaceName,
+			Namespace: c.Namespace,
 			Labels:    c.Labels,
 		},
 		Spec: appsv1.DaemonSetSpec{
@@ -135,7 +226,7 @@ func (c *criEnsurer) Ensure() ([]client.Object, error) {
 							Command: []string{
 								"bash",
 								"-c",
-								fmt.Sprintf("%s/%s %s/%s", workMountPath, reconcileScriptKey, workMountPath, configTomlKey),
+								fmt.Sprintf("%s/%s %s/%s %s/%s %s/%s %s", workMountPath, reconcileScriptKey, workMountPath, configTomlKey, workMountPath, configPathTomlKey, workMountPath, hostsTomlKey, format),
 							},
 							ImagePullPolicy: corev1.PullIfNotPresent,
 							VolumeMounts: []corev1.VolumeMount{
@@ -170,6 +261,14 @@ func (c *criEnsurer) Ensure() ([]client.Object, error) {
 											Key:  configTomlKey,
 											Path: configTomlKey,
 										},
+										{
+											Key:  configPathTomlKey,
+											Path: configPathTomlKey,
+										},
+										{
+											Key:  hostsTomlKey,
+											Path: hostsTomlKey,
+										},
 									},
 									Optional: pointer.Bool(false),
 								},
@@ -195,25 +294,65 @@ func (c *criEnsurer) Ensure() ([]client.Object, error) {
 	}, nil
 }
 
+type criMirror struct {
+	Host     string
+	Endpoint string
+	Fallback string
+}
+
 func (c *criEnsurer) configToml() (string, error) {
-	type criMirror struct {
-		Host     string
-		Endpoint string
+	var buf bytes.Buffer
+	if err := configTemplate.Execute(&buf, c.mirrors()); err != nil {
+		return "", err
 	}
 
+	return buf.String(), nil
+}
+
+// configHosts renders the hosts.toml drop-ins of all mirrors into a single file, separated by
+// "# host:" lines. Unlike the inline format, a hosts.toml has no endpoint list: the cache is an
+// additional host tried for pulls, and "server" is the upstream the pull falls back to (or the
+// cache itself when the fallback is disabled).
+func (c *criEnsurer) configHosts() (string, error) {
+	var buf bytes.Buffer
+	if err := hostsTemplate.Execute(&buf, c.mirrors()); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func (c *criEnsurer) mirrors() []*criMirror {
 	var mirrors []*criMirror
+	if c.DisableMirrorInjection {
+		// return no mirror entries instead of skipping the drop-ins, so that the entries
+		// already applied on the nodes are removed and pulls go to the upstreams again
+		return nil
+	}
 	for i := range c.ReferencedServices.Items {
 		svc := c.ReferencedServices.Items[i]
-		mirrors = append(mirrors, &criMirror{
-			Host:     svc.Labels[registryCacheServiceUpstreamLabel],
+		mirror := &criMirror{
+			Host:     serviceUpstream(&svc),
 			Endpoint: fmt.Sprintf("http://%s:%d", svc.Spec.ClusterIP, svc.Spec.Ports[0].Port),
-		})
+		}
+		// caches deployed in the DaemonSet mode are reached through the node-local host port
+		// instead of the service cluster IP
+		if hostPort, ok := svc.Annotations[registryCacheServiceHostPortAnnotation]; ok {
+			mirror.Endpoint = "http://localhost:" + hostPort
+		}
+		// the upstream itself serves as secondary endpoint unless disabled, so pulls still
+		// succeed directly while the cache is down
+		if svc.Annotations[registryCacheServiceFallbackAnnotation] != "false" {
+			insecure := svc.Annotations[registryCacheServiceInsecureAnnotation] == "true"
+			mirror.Fallback = UpstreamRegistryURL(mirror.Host, insecure)
+		}
+		mirrors = append(mirrors, mirror)
 	}
 
-	var buf bytes.Buffer
-	if err := configTemplate.Execute(&buf, mirrors); err != nil {
-		return "", err
-	}
+	// order the entries by host so that the rendered config is deterministic
+	sort.Slice(mirrors, func(i, j int) bool {
+		return mirrors[i].Host < mirrors[j].Host
+	})
 
-	return buf.String(), nil
+	return mirrors
 }
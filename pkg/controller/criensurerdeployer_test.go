@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -54,9 +55,96 @@ func Test_criEnsurer_configToml(t *testing.T) {
 			},
 			want: `# governed by gardener-extension-registry-cache, do not edit
 [plugins."io.containerd.grpc.v1.cri".registry.mirrors."docker.io"]
-  endpoint = ["http://1.1.1.1:5000"]
+  endpoint = ["http://1.1.1.1:5000", "https://registry-1.docker.io"]
+[plugins."io.containerd.grpc.v1.cri".registry.mirrors."ghcr.io"]
+  endpoint = ["http://2.2.2.2:5001", "https://ghcr.io"]
+`,
+		},
+		{
+			name: "disabled fallback renders only the cache endpoint",
+			services: &corev1.ServiceList{
+				Items: []corev1.Service{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								registryCacheServiceUpstreamLabel: "ghcr.io",
+							},
+							Annotations: map[string]string{
+								registryCacheServiceFallbackAnnotation: "false",
+							},
+						},
+						Spec: corev1.ServiceSpec{
+							ClusterIP: "2.2.2.2",
+							Ports: []corev1.ServicePort{
+								{
+									Port: 5001,
+								},
+							},
+						},
+					},
+				},
+			},
+			want: `# governed by gardener-extension-registry-cache, do not edit
 [plugins."io.containerd.grpc.v1.cri".registry.mirrors."ghcr.io"]
   endpoint = ["http://2.2.2.2:5001"]
+`,
+		},
+		{
+			name: "insecure upstream falls back via plain HTTP",
+			services: &corev1.ServiceList{
+				Items: []corev1.Service{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								registryCacheServiceUpstreamLabel: "registry.internal",
+							},
+							Annotations: map[string]string{
+								registryCacheServiceInsecureAnnotation: "true",
+							},
+						},
+						Spec: corev1.ServiceSpec{
+							ClusterIP: "2.2.2.2",
+							Ports: []corev1.ServicePort{
+								{
+									Port: 5001,
+								},
+							},
+						},
+					},
+				},
+			},
+			want: `# governed by gardener-extension-registry-cache, do not edit
+[plugins."io.containerd.grpc.v1.cri".registry.mirrors."registry.internal"]
+  endpoint = ["http://2.2.2.2:5001", "http://registry.internal"]
+`,
+		},
+		{
+			name: "host port annotation points containerd to localhost",
+			services: &corev1.ServiceList{
+				Items: []corev1.Service{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								registryCacheServiceUpstreamLabel: "ghcr.io",
+							},
+							Annotations: map[string]string{
+								registryCacheServiceHostPortAnnotation: "5010",
+							},
+						},
+						Spec: corev1.ServiceSpec{
+							ClusterIP: "2.2.2.2",
+							Ports: []corev1.ServicePort{
+								{
+									Port: 5001,
+								},
+							},
+						},
+					},
+				},
+			},
+			want: `# governed by gardener-extension-registry-cache, do not edit
+[plugins."io.containerd.grpc.v1.cri".registry.mirrors."ghcr.io"]
+  endpoint = ["http://localhost:5010", "https://ghcr.io"]
 `,
 		},
 	}
@@ -77,3 +165,114 @@ func Test_criEnsurer_configToml(t *testing.T) {
 		})
 	}
 }
+
+// TODO: migrate to ginkgo test
+func Test_criEnsurer_configHosts(t *testing.T) {
+	c := &criEnsurer{
+		ReferencedServices: &corev1.ServiceList{
+			Items: []corev1.Service{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							registryCacheServiceUpstreamLabel: "docker.io",
+						},
+					},
+					Spec: corev1.ServiceSpec{
+						ClusterIP: "1.1.1.1",
+						Ports: []corev1.ServicePort{
+							{
+								Port: 5000,
+							},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							registryCacheServiceUpstreamLabel: "ghcr.io",
+						},
+						Annotations: map[string]string{
+							registryCacheServiceFallbackAnnotation: "false",
+						},
+					},
+					Spec: corev1.ServiceSpec{
+						ClusterIP: "2.2.2.2",
+						Ports: []corev1.ServicePort{
+							{
+								Port: 5001,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	got, err := c.configHosts()
+	if err != nil {
+		t.Fatalf("criEnsurer.configHosts() error = %v", err)
+	}
+
+	// the upstream serves as fallback server for docker.io; ghcr.io has the fallback
+	// disabled, so its server stays the cache itself
+	want := `# host: docker.io
+# governed by gardener-extension-registry-cache, do not edit
+server = "https://registry-1.docker.io"
+
+[host."http://1.1.1.1:5000"]
+  capabilities = ["pull", "resolve"]
+# host: ghcr.io
+# governed by gardener-extension-registry-cache, do not edit
+server = "http://2.2.2.2:5001"
+
+[host."http://2.2.2.2:5001"]
+  capabilities = ["pull", "resolve"]
+`
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("criEnsurer.configHosts() = %v", diff)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_criEnsurer_configTomlDisableMirrorInjection(t *testing.T) {
+	c := &criEnsurer{
+		ReferencedServices: &corev1.ServiceList{
+			Items: []corev1.Service{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							registryCacheServiceUpstreamLabel: "docker.io",
+						},
+					},
+					Spec: corev1.ServiceSpec{
+						ClusterIP: "1.1.1.1",
+						Ports: []corev1.ServicePort{
+							{
+								Port: 5000,
+							},
+						},
+					},
+				},
+			},
+		},
+		DisableMirrorInjection: true,
+	}
+
+	got, err := c.configToml()
+	if err != nil {
+		t.Fatalf("criEnsurer.configToml() error = %v", err)
+	}
+	if want := "# governed by gardener-extension-registry-cache, do not edit\n"; got != want {
+		t.Errorf("criEnsurer.configToml() = %q, want the header without any mirror entries", got)
+	}
+
+	// re-enabling the mirror injection renders the entries again
+	c.DisableMirrorInjection = false
+	got, err = c.configToml()
+	if err != nil {
+		t.Fatalf("criEnsurer.configToml() error = %v", err)
+	}
+	if !strings.Contains(got, `mirrors."docker.io"`) {
+		t.Errorf("criEnsurer.configToml() = %q, want the docker.io mirror entry", got)
+	}
+}
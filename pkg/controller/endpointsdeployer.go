@@ -0,0 +1,58 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// endpointsConfigMapName is the name of the ConfigMap in kube-system publishing the cache
+	// endpoints for other tools in the shoot to consume.
+	endpointsConfigMapName = "registry-cache-endpoints"
+	// endpointsDataKey is the data key holding a JSON object mapping each upstream to its cache
+	// endpoint, e.g. {"docker.io": "http://10.4.5.6:5000"}.
+	endpointsDataKey = "endpoints.json"
+)
+
+// endpointsConfigMap builds the ConfigMap publishing the upstream to cache endpoint mapping of
+// the given registry cache services. The ConfigMap is part of the ensurer ManagedResource, so
+// endpoints of removed caches are pruned automatically.
+func endpointsConfigMap(services *corev1.ServiceList) (*corev1.ConfigMap, error) {
+	endpoints := map[string]string{}
+	for i := range services.Items {
+		service := services.Items[i]
+		endpoints[serviceUpstream(&service)] = fmt.Sprintf("http://%s:%d", service.Spec.ClusterIP, service.Spec.Ports[0].Port)
+	}
+
+	raw, err := json.MarshalIndent(endpoints, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal cache endpoints: %w", err)
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      endpointsConfigMapName,
+			Namespace: metav1.NamespaceSystem,
+		},
+		Data: map[string]string{
+			endpointsDataKey: string(raw),
+		},
+	}, nil
+}
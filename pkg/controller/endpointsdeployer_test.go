@@ -0,0 +1,72 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TODO: migrate to ginkgo test
+func Test_endpointsConfigMap(t *testing.T) {
+	services := &corev1.ServiceList{
+		Items: []corev1.Service{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{registryCacheServiceUpstreamLabel: "docker.io"},
+				},
+				Spec: corev1.ServiceSpec{
+					ClusterIP: "10.4.5.6",
+					Ports:     []corev1.ServicePort{{Port: 5000}},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{registryCacheServiceUpstreamLabel: "quay.io"},
+				},
+				Spec: corev1.ServiceSpec{
+					ClusterIP: "10.4.5.7",
+					Ports:     []corev1.ServicePort{{Port: 5000}},
+				},
+			},
+		},
+	}
+
+	configMap, err := endpointsConfigMap(services)
+	if err != nil {
+		t.Fatalf("endpointsConfigMap() error = %v", err)
+	}
+
+	if configMap.Name != endpointsConfigMapName || configMap.Namespace != metav1.NamespaceSystem {
+		t.Errorf("expected ConfigMap %s/%s, got %s/%s", metav1.NamespaceSystem, endpointsConfigMapName, configMap.Namespace, configMap.Name)
+	}
+
+	var endpoints map[string]string
+	if err := json.Unmarshal([]byte(configMap.Data[endpointsDataKey]), &endpoints); err != nil {
+		t.Fatalf("could not unmarshal %s: %v", endpointsDataKey, err)
+	}
+
+	want := map[string]string{
+		"docker.io": "http://10.4.5.6:5000",
+		"quay.io":   "http://10.4.5.7:5000",
+	}
+	if diff := cmp.Diff(want, endpoints); diff != "" {
+		t.Errorf("unexpected endpoints (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// eventReasonCacheCreated is emitted when a cache is deployed for the first time.
+	eventReasonCacheCreated = "CacheCreated"
+	// eventReasonVolumeResized is emitted when the size of the cache volume changes.
+	eventReasonVolumeResized = "VolumeResized"
+	// eventReasonUpstreamUnreachable is emitted when the upstream of a cache cannot be
+	// resolved during reconciliation.
+	eventReasonUpstreamUnreachable = "UpstreamUnreachable"
+
+	// eventComponent is the reporting component of the emitted events.
+	eventComponent = "registry-cache-extension"
+)
+
+// cacheMilestone is a noteworthy lifecycle event of a cache that is surfaced as a Kubernetes
+// Event in the shoot, so that users see it in `kubectl get events` without access to the
+// metrics of the extension.
+type cacheMilestone struct {
+	EventType string
+	Reason    string
+	Message   string
+}
+
+// cacheMilestones derives the milestones of a cache from the difference between the desired
+// and the currently deployed StatefulSet. A nil deployed StatefulSet means the cache is
+// created with this reconciliation.
+func cacheMilestones(upstream string, desired, deployed *appsv1.StatefulSet) []cacheMilestone {
+	if deployed == nil {
+		return []cacheMilestone{{
+			EventType: corev1.EventTypeNormal,
+			Reason:    eventReasonCacheCreated,
+			Message:   fmt.Sprintf("Registry cache for upstream %s created.", upstream),
+		}}
+	}
+
+	var milestones []cacheMilestone
+
+	desiredSize := claimTemplateSize(desired)
+	deployedSize := claimTemplateSize(deployed)
+	if desiredSize != nil && deployedSize != nil && desiredSize.Cmp(*deployedSize) != 0 {
+		milestones = append(milestones, cacheMilestone{
+			EventType: corev1.EventTypeNormal,
+			Reason:    eventReasonVolumeResized,
+			Message:   fmt.Sprintf("Cache volume of upstream %s resized from %s to %s.", upstream, deployedSize.String(), desiredSize.String()),
+		})
+	}
+
+	return milestones
+}
+
+// emitCacheEvents creates an Event in the shoot for every given milestone. The events are
+// attached to the Service of the cache, the well-known object users interact with. Event
+// creation is best-effort and never fails the reconciliation.
+func (a *actuator) emitCacheEvents(ctx context.Context, log logr.Logger, shootClient client.Client, namespace, upstream string, milestones []cacheMilestone) {
+	for _, milestone := range milestones {
+		if err := shootClient.Create(ctx, newCacheEvent(namespace, cacheObjectName(upstream), milestone, a.now())); err != nil {
+			log.Error(err, "could not emit cache event", "upstream", upstream, "reason", milestone.Reason)
+		}
+	}
+}
+
+// newCacheEvent builds the Event of a milestone on the Service of the cache. The name carries
+// the timestamp so that repeated milestones do not overwrite each other.
+func newCacheEvent(namespace, cacheName string, milestone cacheMilestone, now time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", cacheName, now.UnixNano()),
+			Namespace: namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Service",
+			Name:       cacheName,
+			Namespace:  namespace,
+		},
+		Type:           milestone.EventType,
+		Reason:         milestone.Reason,
+		Message:        milestone.Message,
+		FirstTimestamp: metav1.NewTime(now),
+		LastTimestamp:  metav1.NewTime(now),
+		Count:          1,
+		Source: corev1.EventSource{
+			Component: eventComponent,
+		},
+	}
+}
+
+// claimTemplateSize returns the requested storage of the cache volume claim template, or nil
+// for caches without one (e.g. in DaemonSet mode).
+func claimTemplateSize(statefulSet *appsv1.StatefulSet) *resource.Quantity {
+	if len(statefulSet.Spec.VolumeClaimTemplates) == 0 {
+		return nil
+	}
+	size, ok := statefulSet.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
+	if !ok {
+		return nil
+	}
+	return &size
+}
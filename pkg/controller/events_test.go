@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// TODO: migrate to ginkgo test
+func Test_cacheMilestones(t *testing.T) {
+	statefulSetWith := func(size string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			Spec: appsv1.StatefulSetSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "registry"}},
+					},
+				},
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+					Spec: corev1.PersistentVolumeClaimSpec{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceStorage: resource.MustParse(size),
+							},
+						},
+					},
+				}},
+			},
+		}
+	}
+
+	milestones := cacheMilestones("docker.io", statefulSetWith("10Gi"), nil)
+	if len(milestones) != 1 || milestones[0].Reason != eventReasonCacheCreated {
+		t.Errorf("expected a single creation milestone, got %v", milestones)
+	}
+
+	milestones = cacheMilestones("docker.io", statefulSetWith("10Gi"), statefulSetWith("10Gi"))
+	if len(milestones) != 0 {
+		t.Errorf("expected no milestones for an unchanged cache, got %v", milestones)
+	}
+
+	milestones = cacheMilestones("docker.io", statefulSetWith("20Gi"), statefulSetWith("10Gi"))
+	if len(milestones) != 1 || milestones[0].Reason != eventReasonVolumeResized {
+		t.Errorf("expected a single resize milestone, got %v", milestones)
+	}
+	if !strings.Contains(milestones[0].Message, "from 10Gi to 20Gi") {
+		t.Errorf("expected the resize message to carry both sizes, got %q", milestones[0].Message)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_newCacheEvent(t *testing.T) {
+	now := time.Unix(1663161600, 0)
+	event := newCacheEvent("registry-cache", "registry-docker-io", cacheMilestone{
+		EventType: corev1.EventTypeNormal,
+		Reason:    eventReasonCacheCreated,
+		Message:   "Registry cache for upstream docker.io created.",
+	}, now)
+
+	if !strings.HasPrefix(event.Name, "registry-docker-io.") {
+		t.Errorf("expected the event name to be derived from the cache name, got %q", event.Name)
+	}
+	if event.InvolvedObject.Kind != "Service" || event.InvolvedObject.Name != "registry-docker-io" || event.InvolvedObject.Namespace != "registry-cache" {
+		t.Errorf("expected the event to involve the cache service, got %+v", event.InvolvedObject)
+	}
+	if event.Reason != eventReasonCacheCreated || event.Type != corev1.EventTypeNormal {
+		t.Errorf("unexpected event reason or type: %q, %q", event.Reason, event.Type)
+	}
+	if !event.FirstTimestamp.Time.Equal(now) || event.Count != 1 {
+		t.Errorf("unexpected event timestamp or count: %v, %d", event.FirstTimestamp, event.Count)
+	}
+}
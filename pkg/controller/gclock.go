@@ -0,0 +1,59 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// activeGCJobs lists the garbage collection Jobs in the cache namespace and reports the
+// caches with a run currently in progress, keyed by cache name. Both the Jobs spawned by the
+// scheduled CronJob and the one-off Job of a manual run carry the shared "app: <cache>-gc"
+// label, so a single list covers both kinds of runs.
+func activeGCJobs(ctx context.Context, c client.Client, cacheNamespace string) (map[string]bool, error) {
+	jobs := &batchv1.JobList{}
+	if err := c.List(ctx, jobs, client.InNamespace(cacheNamespace)); err != nil {
+		return nil, err
+	}
+
+	active := map[string]bool{}
+	for _, job := range jobs.Items {
+		app := job.Labels["app"]
+		if strings.HasSuffix(app, "-gc") && job.Status.Active > 0 {
+			active[strings.TrimSuffix(app, "-gc")] = true
+		}
+	}
+	return active, nil
+}
+
+// serializeGCAndUpdate coordinates garbage collection runs and configuration rollouts of a
+// cache so that they never overlap on the shared volume. The lock is the deployed StatefulSet
+// itself, there is no extra lease object: while a garbage collection Job is active, the
+// deployed pod template is kept and the pending configuration change is re-applied by a later
+// reconciliation, once the run has finished.
+func serializeGCAndUpdate(desired, deployed *appsv1.StatefulSet, gcInProgress bool) {
+	if deployed == nil || !gcInProgress {
+		return
+	}
+
+	// a pod template change while garbage collection rewrites the blob store can leave the
+	// volume corrupted, so the rollout waits for the run to finish
+	desired.Spec.Template = *deployed.Spec.Template.DeepCopy()
+}
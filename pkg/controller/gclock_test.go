@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+// TODO: migrate to ginkgo test
+func Test_serializeGCAndUpdate(t *testing.T) {
+	statefulSetWith := func(image string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: pointer.Int32(1),
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "registry", Image: image}},
+					},
+				},
+			},
+			Status: appsv1.StatefulSetStatus{
+				Replicas:        1,
+				UpdatedReplicas: 1,
+			},
+		}
+	}
+
+	// a configuration change while garbage collection is running must not roll the pod
+	desired := statefulSetWith("registry:2.2")
+	deployed := statefulSetWith("registry:2.1")
+	serializeGCAndUpdate(desired, deployed, true)
+	if got := desired.Spec.Template.Spec.Containers[0].Image; got != "registry:2.1" {
+		t.Errorf("image = %q, want the deployed registry:2.1 while garbage collection is running", got)
+	}
+
+	// without a running garbage collection the configuration change goes through
+	desired = statefulSetWith("registry:2.2")
+	serializeGCAndUpdate(desired, deployed, false)
+	if got := desired.Spec.Template.Spec.Containers[0].Image; got != "registry:2.2" {
+		t.Errorf("image = %q, want the desired registry:2.2 without a running garbage collection", got)
+	}
+
+	// a cache deployed for the first time has nothing to hold back
+	desired = statefulSetWith("registry:2.2")
+	serializeGCAndUpdate(desired, nil, true)
+	if got := desired.Spec.Template.Spec.Containers[0].Image; got != "registry:2.2" {
+		t.Errorf("image = %q, want the desired registry:2.2 for a new cache", got)
+	}
+}
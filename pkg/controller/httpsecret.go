@@ -0,0 +1,68 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// registryCacheHTTPSecretName is the name of the Secret holding distribution's http.secret
+	// shared by all registry cache replicas.
+	registryCacheHTTPSecretName = "registry-cache-http-secret"
+	// registryCacheHTTPSecretKey is the data key of the http.secret value.
+	registryCacheHTTPSecretKey = "haSharedSecret"
+)
+
+// httpSecret returns the Secret holding distribution's http.secret. The value is read from the
+// shoot if the Secret already exists so that reconciliations do not rotate it - an unstable
+// http.secret breaks client upload state when a cache runs with multiple replicas. It is
+// generated only when the Secret is missing.
+func httpSecret(ctx context.Context, shootClient client.Client, namespace string) (*corev1.Secret, error) {
+	var value []byte
+
+	existing := &corev1.Secret{}
+	err := shootClient.Get(ctx, client.ObjectKey{Name: registryCacheHTTPSecretName, Namespace: namespace}, existing)
+	switch {
+	case err == nil && len(existing.Data[registryCacheHTTPSecretKey]) > 0:
+		value = existing.Data[registryCacheHTTPSecretKey]
+	case err == nil || apierrors.IsNotFound(err):
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return nil, fmt.Errorf("unable to generate http secret: %w", err)
+		}
+		value = []byte(hex.EncodeToString(random))
+	default:
+		return nil, fmt.Errorf("unable to read http secret: %w", err)
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      registryCacheHTTPSecretName,
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			registryCacheHTTPSecretKey: value,
+		},
+	}, nil
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TODO: migrate to ginkgo test
+func Test_httpSecret_StableAcrossReconciles(t *testing.T) {
+	ctx := context.Background()
+	shootClient := fakeclient.NewClientBuilder().Build()
+
+	secret, err := httpSecret(ctx, shootClient, registryCacheNamespaceName)
+	if err != nil {
+		t.Fatalf("httpSecret() error = %v", err)
+	}
+
+	value := secret.Data[registryCacheHTTPSecretKey]
+	if len(value) == 0 {
+		t.Fatal("expected a generated http secret value")
+	}
+
+	if err := shootClient.Create(ctx, secret); err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err = httpSecret(ctx, shootClient, registryCacheNamespaceName)
+	if err != nil {
+		t.Fatalf("httpSecret() error = %v", err)
+	}
+	if got := secret.Data[registryCacheHTTPSecretKey]; string(got) != string(value) {
+		t.Errorf("expected the http secret to be stable across reconciles, got %q, want %q", got, value)
+	}
+}
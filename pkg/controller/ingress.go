@@ -0,0 +1,77 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha1"
+)
+
+// cacheIngressName is the name of the shared Ingress in front of all caches, see cacheIngress.
+const cacheIngressName = "registry-cache"
+
+// cacheIngressPathPrefix returns the path prefix the cache is served under on the shared
+// Ingress: the configured ingressPathPrefix, or "/" followed by the upstream.
+func cacheIngressPathPrefix(cache v1alpha1.RegistryCache) string {
+	if cache.IngressPathPrefix != nil {
+		return *cache.IngressPathPrefix
+	}
+	return "/" + cache.Upstream
+}
+
+// cacheIngress returns the shared path-based Ingress exposing all caches under one host. Each
+// cache gets a regex rule routing "/<prefix>/v2/..." to its Service; the ingress controller
+// strips the prefix via the rewrite-target annotation before the request reaches the registry,
+// so the registry containers keep serving the plain "/v2/..." API that probes, the mirror
+// endpoints and the smoke test rely on. The rewrite annotations are ingress-nginx specific,
+// which matches the ingress class the feature is documented for.
+func cacheIngress(namespace string, config *v1alpha1.Ingress, caches []v1alpha1.RegistryCache) *networkingv1.Ingress {
+	pathTypeImplementationSpecific := networkingv1.PathTypeImplementationSpecific
+	paths := make([]networkingv1.HTTPIngressPath, 0, len(caches))
+	for _, cache := range caches {
+		paths = append(paths, networkingv1.HTTPIngressPath{
+			Path:     cacheIngressPathPrefix(cache) + "(/|$)(.*)",
+			PathType: &pathTypeImplementationSpecific,
+			Backend: networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{
+					Name: cacheObjectName(cache.Upstream),
+					Port: networkingv1.ServiceBackendPort{Name: registryCacheInternalName},
+				},
+			},
+		})
+	}
+
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cacheIngressName,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/use-regex":      "true",
+				"nginx.ingress.kubernetes.io/rewrite-target": "/$2",
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: config.ClassName,
+			Rules: []networkingv1.IngressRule{{
+				Host: config.Host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{Paths: paths},
+				},
+			}},
+		},
+	}
+}
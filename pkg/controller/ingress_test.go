@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha1"
+)
+
+// TODO: migrate to ginkgo test
+func Test_cacheIngress(t *testing.T) {
+	ingress := cacheIngress("registry-cache", &v1alpha1.Ingress{Host: "registry.example.com", ClassName: pointer.String("nginx")}, []v1alpha1.RegistryCache{
+		{Upstream: "docker.io"},
+		{Upstream: "eu.gcr.io", IngressPathPrefix: pointer.String("/gcr")},
+	})
+
+	if ingress.Spec.IngressClassName == nil || *ingress.Spec.IngressClassName != "nginx" {
+		t.Errorf("expected the configured ingress class nginx, got %v", ingress.Spec.IngressClassName)
+	}
+	if len(ingress.Spec.Rules) != 1 || ingress.Spec.Rules[0].Host != "registry.example.com" {
+		t.Fatalf("expected a single rule for the configured host, got %+v", ingress.Spec.Rules)
+	}
+	if got := ingress.Annotations["nginx.ingress.kubernetes.io/rewrite-target"]; got != "/$2" {
+		t.Errorf("rewrite-target annotation = %q, want /$2 so the registry serves the plain /v2/ API", got)
+	}
+
+	paths := ingress.Spec.Rules[0].HTTP.Paths
+	if len(paths) != 2 {
+		t.Fatalf("expected a path per cache, got %+v", paths)
+	}
+	if paths[0].Path != "/docker.io(/|$)(.*)" || paths[0].Backend.Service.Name != "registry-docker-io" || paths[0].Backend.Service.Port.Name != registryCacheInternalName {
+		t.Errorf("expected the defaulted /docker.io regex path routing to the cache service, got %+v", paths[0])
+	}
+	if paths[0].PathType == nil || *paths[0].PathType != networkingv1.PathTypeImplementationSpecific {
+		t.Errorf("expected the ImplementationSpecific path type, got %v", paths[0].PathType)
+	}
+	if paths[1].Path != "/gcr(/|$)(.*)" || paths[1].Backend.Service.Name != "registry-eu-gcr-io" {
+		t.Errorf("expected the configured /gcr regex path routing to the cache service, got %+v", paths[1])
+	}
+}
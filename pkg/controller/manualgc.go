@@ -0,0 +1,96 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha1"
+)
+
+// garbageCollectAnnotation requests a one-off garbage collection run for the cache of the
+// upstream given as value, without waiting for the garbage collection schedule. Unlike
+// evictRepositoryAnnotation, the annotation is removed by the controller once the generated
+// Job has finished, so it is a fire-and-forget operational escape hatch; setting it again
+// after the Job has been cleaned up starts a fresh run.
+const garbageCollectAnnotation = "registry-cache.extensions.gardener.cloud/garbage-collect"
+
+// resolveGCTarget validates the value of the garbage collection annotation. The upstream must
+// have a configured cache in the StatefulSet mode; caches in the DaemonSet mode have no volume
+// claim a garbage collection pod could mount, so a manual run is rejected for them instead of
+// generating a Job that can never start. An empty target means no manual run is requested.
+func resolveGCTarget(target string, registryConfig *v1alpha1.RegistryConfig) (string, error) {
+	if target == "" {
+		return "", nil
+	}
+
+	for _, cache := range registryConfig.Caches {
+		if cache.Upstream == target {
+			if pointer.StringDeref(cache.Mode, v1alpha1.ModeStatefulSet) == v1alpha1.ModeDaemonSet {
+				return "", fmt.Errorf("the cache for upstream %q runs in the DaemonSet mode and cannot be garbage collected manually", target)
+			}
+			return target, nil
+		}
+	}
+
+	return "", fmt.Errorf("no cache is configured for upstream %q to garbage collect", target)
+}
+
+// clearFinishedManualGC removes the garbage collection annotation from the Extension once the
+// Job generated for it has finished, so that the Job is dropped from the ManagedResource with
+// the next reconciliation and a later annotation starts a fresh run. While the Job has not
+// been created or is still running, the annotation is kept.
+func (a *actuator) clearFinishedManualGC(ctx context.Context, log logr.Logger, shootClient client.Client, ex *extensionsv1alpha1.Extension, namespace string) error {
+	upstream := ex.GetAnnotations()[garbageCollectAnnotation]
+	if upstream == "" {
+		return nil
+	}
+
+	job := &batchv1.Job{}
+	if err := shootClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: manualGCJobName(upstream)}, job); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !jobFinished(job) {
+		return nil
+	}
+
+	log.Info("manual garbage collection finished, removing the annotation", "upstream", upstream)
+	patch := client.MergeFrom(ex.DeepCopy())
+	delete(ex.Annotations, garbageCollectAnnotation)
+	return a.client.Patch(ctx, ex, patch)
+}
+
+// jobFinished reports whether the Job has run to completion, successfully or not. A failed
+// garbage collection also clears the annotation; retrying is the operator's call.
+func jobFinished(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
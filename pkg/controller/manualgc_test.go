@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha1"
+)
+
+// TODO: migrate to ginkgo test
+func Test_resolveGCTarget(t *testing.T) {
+	registryConfig := &v1alpha1.RegistryConfig{
+		Caches: []v1alpha1.RegistryCache{{Upstream: "docker.io"}},
+	}
+
+	if upstream, err := resolveGCTarget("", registryConfig); err != nil || upstream != "" {
+		t.Errorf("resolveGCTarget(\"\") = %q, %v, want no target and no error", upstream, err)
+	}
+	if upstream, err := resolveGCTarget("docker.io", registryConfig); err != nil || upstream != "docker.io" {
+		t.Errorf("resolveGCTarget(docker.io) = %q, %v, want the configured upstream", upstream, err)
+	}
+	if _, err := resolveGCTarget("ghcr.io", registryConfig); err == nil {
+		t.Error("expected an error for an upstream without a configured cache")
+	}
+
+	registryConfig.Caches[0].Mode = pointer.String(v1alpha1.ModeDaemonSet)
+	if _, err := resolveGCTarget("docker.io", registryConfig); err == nil {
+		t.Error("expected an error for a cache in the DaemonSet mode")
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_actuator_clearFinishedManualGC(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(batchv1.AddToScheme(scheme))
+	utilruntime.Must(extensionsv1alpha1.AddToScheme(scheme))
+
+	newExtension := func() *extensionsv1alpha1.Extension {
+		return &extensionsv1alpha1.Extension{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "registry-cache",
+				Namespace:   "shoot--foo--bar",
+				Annotations: map[string]string{garbageCollectAnnotation: "docker.io"},
+			},
+		}
+	}
+	gcJob := func(conditionType batchv1.JobConditionType) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      manualGCJobName("docker.io"),
+				Namespace: registryCacheNamespaceName,
+			},
+			Status: batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{{Type: conditionType, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		job            *batchv1.Job
+		wantAnnotation bool
+	}{
+		{
+			name:           "the annotation is kept while the job has not been created yet",
+			wantAnnotation: true,
+		},
+		{
+			name:           "the annotation is removed after a completed run",
+			job:            gcJob(batchv1.JobComplete),
+			wantAnnotation: false,
+		},
+		{
+			name:           "the annotation is removed after a failed run",
+			job:            gcJob(batchv1.JobFailed),
+			wantAnnotation: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ex := newExtension()
+			seedClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ex).Build()
+			shootBuilder := fake.NewClientBuilder().WithScheme(scheme)
+			if tt.job != nil {
+				shootBuilder = shootBuilder.WithObjects(tt.job)
+			}
+			a := &actuator{client: seedClient}
+
+			if err := a.clearFinishedManualGC(context.Background(), logr.Discard(), shootBuilder.Build(), ex, registryCacheNamespaceName); err != nil {
+				t.Fatalf("clearFinishedManualGC() failed: %v", err)
+			}
+
+			updated := &extensionsv1alpha1.Extension{}
+			if err := seedClient.Get(context.Background(), client.ObjectKeyFromObject(ex), updated); err != nil {
+				t.Fatalf("extension cannot be read back: %v", err)
+			}
+			if _, ok := updated.Annotations[garbageCollectAnnotation]; ok != tt.wantAnnotation {
+				t.Errorf("annotation present = %v, want %v", ok, tt.wantAnnotation)
+			}
+		})
+	}
+}
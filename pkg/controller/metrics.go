@@ -0,0 +1,169 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	appsv1 "k8s.io/api/apps/v1"
+	equality "k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// driftGauge indicates per cache whether the deployed StatefulSet drifted from the desired
+// spec, e.g. because of a manual edit in the shoot. It is computed on every reconciliation by
+// comparing the desired objects against the live ones, see statefulSetDrifted.
+var driftGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "registry_cache_configuration_drift",
+	Help: "Whether the deployed StatefulSet of a registry cache drifted from the desired spec (1 = drifted).",
+}, []string{"upstream"})
+
+func init() {
+	metrics.Registry.MustRegister(driftGauge)
+}
+
+// statefulSetDrifted reports whether the deployed StatefulSet drifted from the desired one.
+// Only the fields managed by the extension are compared: the registry container image, its
+// environment and resources, and the requested volume size. Everything else is either
+// defaulted by the API server or owned by other controllers and would produce false positives.
+func statefulSetDrifted(desired, deployed *appsv1.StatefulSet) bool {
+	if len(deployed.Spec.Template.Spec.Containers) == 0 {
+		return true
+	}
+
+	desiredContainer := desired.Spec.Template.Spec.Containers[0]
+	deployedContainer := deployed.Spec.Template.Spec.Containers[0]
+	if deployedContainer.Image != desiredContainer.Image {
+		return true
+	}
+	if !equality.Semantic.DeepEqual(deployedContainer.Env, desiredContainer.Env) {
+		return true
+	}
+	if !equality.Semantic.DeepEqual(deployedContainer.Resources, desiredContainer.Resources) {
+		return true
+	}
+
+	if len(deployed.Spec.VolumeClaimTemplates) != len(desired.Spec.VolumeClaimTemplates) {
+		return true
+	}
+	for i := range desired.Spec.VolumeClaimTemplates {
+		if !equality.Semantic.DeepEqual(deployed.Spec.VolumeClaimTemplates[i].Spec.Resources.Requests, desired.Spec.VolumeClaimTemplates[i].Spec.Resources.Requests) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pulledBytesQuery sums the response sizes of the blob handler to the cumulative bytes served
+// from the caches, labeled by upstream_host. Image content is served almost entirely through
+// blobs, so the histogram sum of the blob handler is the closest the registry gets to a
+// "bytes pulled" counter.
+const pulledBytesQuery = `sum by (upstream_host) (registry_http_response_size_bytes_sum{handler="blob"})`
+
+// volumeAvailableBytesQuery aggregates the kubelet volume stats of the shoot to the available
+// bytes per PersistentVolumeClaim of the given namespace, see queryVolumeAvailableBytes.
+const volumeAvailableBytesQuery = `min by (persistentvolumeclaim) (kubelet_volume_stats_available_bytes{namespace=%q})`
+
+// queryPulledBytes queries the shoot Prometheus in the given control plane namespace for the
+// cumulative bytes served per upstream. The result is keyed by the (sanitized) upstream_host
+// label of the metric.
+func queryPulledBytes(ctx context.Context, namespace string) (map[string]int64, error) {
+	return queryUpstreamVector(ctx, namespace, pulledBytesQuery)
+}
+
+// queryVolumeAvailableBytes queries the shoot Prometheus in the given control plane namespace
+// for the free bytes of the cache volumes, keyed by the name of the PersistentVolumeClaim. The
+// underlying kubelet volume stats are reported per mounted volume, so a volume of a pending or
+// unscheduled cache pod has no sample.
+func queryVolumeAvailableBytes(ctx context.Context, namespace, cacheNamespace string) (map[string]int64, error) {
+	return queryVector(ctx, namespace, fmt.Sprintf(volumeAvailableBytesQuery, cacheNamespace), "persistentvolumeclaim")
+}
+
+// queryUpstreamVector runs the given instant vector query and keys the result by the
+// upstream_host label of the samples.
+func queryUpstreamVector(ctx context.Context, namespace, query string) (map[string]int64, error) {
+	return queryVector(ctx, namespace, query, "upstream_host")
+}
+
+func queryVector(ctx context.Context, namespace, query, keyLabel string) (map[string]int64, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	queryURL := fmt.Sprintf("http://prometheus-web.%s/api/v1/query?query=%s", namespace, url.QueryEscape(query))
+	request, err := http.NewRequestWithContext(queryCtx, http.MethodGet, queryURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("prometheus query returned status %d", response.StatusCode)
+	}
+
+	return parsePrometheusVector(response.Body, keyLabel)
+}
+
+// parsePrometheusVector parses an instant vector from a Prometheus query API response into a
+// map keyed by the given label. Samples without the label are skipped.
+func parsePrometheusVector(body io.Reader, keyLabel string) (map[string]int64, error) {
+	var response struct {
+		Status string `json:"status"`
+		Data   struct {
+			Result []struct {
+				Metric map[string]string `json:"metric"`
+				Value  []interface{}     `json:"value"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode prometheus response: %w", err)
+	}
+	if response.Status != "success" {
+		return nil, fmt.Errorf("prometheus query was not successful: %s", response.Status)
+	}
+
+	valuesByKey := map[string]int64{}
+	for _, sample := range response.Data.Result {
+		key, ok := sample.Metric[keyLabel]
+		if !ok || len(sample.Value) != 2 {
+			continue
+		}
+		value, ok := sample.Value[1].(string)
+		if !ok {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse sample value %q: %w", value, err)
+		}
+		valuesByKey[key] = int64(parsed)
+	}
+
+	return valuesByKey, nil
+}
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gardener/gardener/pkg/utils/imagevector"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// TODO: migrate to ginkgo test
+func Test_parsePrometheusVector(t *testing.T) {
+	body := `{
+		"status": "success",
+		"data": {
+			"resultType": "vector",
+			"result": [
+				{"metric": {"upstream_host": "docker.io"}, "value": [1663161600, "1073741824"]},
+				{"metric": {"upstream_host": "quay.io"}, "value": [1663161600, "2048"]},
+				{"metric": {}, "value": [1663161600, "1"]}
+			]
+		}
+	}`
+
+	got, err := parsePrometheusVector(strings.NewReader(body), "upstream_host")
+	if err != nil {
+		t.Fatalf("parsePrometheusVector() error = %v", err)
+	}
+	want := map[string]int64{
+		"docker.io": 1073741824,
+		"quay.io":   2048,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePrometheusVector() = %v, want %v", got, want)
+	}
+
+	if _, err := parsePrometheusVector(strings.NewReader(`{"status": "error"}`), "upstream_host"); err == nil {
+		t.Error("expected an error for an unsuccessful query")
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_statefulSetDrifted(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+	desired := ensureStatefulSet(t, c)
+
+	deployed := desired.DeepCopy()
+	if statefulSetDrifted(desired, deployed) {
+		t.Error("expected no drift for an unmodified StatefulSet")
+	}
+
+	deployed.Labels = map[string]string{"edited": "true"}
+	if statefulSetDrifted(desired, deployed) {
+		t.Error("expected no drift for fields not managed by the extension")
+	}
+
+	deployed = desired.DeepCopy()
+	deployed.Spec.Template.Spec.Containers[0].Image = "registry:edited"
+	if !statefulSetDrifted(desired, deployed) {
+		t.Error("expected drift for an edited container image")
+	}
+
+	deployed = desired.DeepCopy()
+	deployed.Spec.Template.Spec.Containers[0].Env = append(deployed.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "EDITED", Value: "true"})
+	if !statefulSetDrifted(desired, deployed) {
+		t.Error("expected drift for an edited container environment")
+	}
+
+	deployed = desired.DeepCopy()
+	deployed.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("20Gi")
+	if !statefulSetDrifted(desired, deployed) {
+		t.Error("expected drift for an edited volume size")
+	}
+}
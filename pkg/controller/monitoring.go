@@ -0,0 +1,362 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
+	"github.com/gardener/gardener/pkg/controllerutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// monitoringConfigMapName is the name of the ConfigMap picked up by the shoot Prometheus.
+	monitoringConfigMapName = "extension-registry-cache-monitoring"
+	// monitoringScrapeConfigKey is the data key holding the scrape config for the registry caches.
+	monitoringScrapeConfigKey = "scrape_config"
+	// monitoringAlertingRulesKey is the data key holding the alerting and recording rules for the
+	// registry caches.
+	monitoringAlertingRulesKey = "alerting_rules"
+)
+
+// alertingRules are the recording and alerting rules for the registry caches. The recording rule
+// correlates failing requests with the cache still serving others: cache hits are answered from
+// the volume with 2xx while fetches from an unreachable upstream surface as 5xx, so a cache in
+// this state keeps serving (possibly stale) cached content. The informational alert
+// distinguishes this state from the cache being down altogether.
+const alertingRules = `registry-cache.rules.yaml: |
+  groups:
+  - name: registry-cache.rules
+    rules:
+    - record: registry_cache:serving_while_upstream_unreachable
+      expr: (sum by (upstream_host) (rate(registry_http_requests_total{code=~"2.."}[5m])) > 0) and on (upstream_host) (sum by (upstream_host) (rate(registry_http_requests_total{code=~"5.."}[5m])) > 0)
+    - alert: RegistryCacheServingStaleContent
+      expr: registry_cache:serving_while_upstream_unreachable > 0
+      for: 15m
+      labels:
+        severity: info
+        type: shoot
+        visibility: owner
+      annotations:
+        summary: Registry cache serves possibly stale content.
+        description: The registry cache for upstream {{ $labels.upstream_host }} answers part of its requests with server errors, typically because the upstream cannot be reached, but still serves cached content. That content may be outdated until the upstream is reachable again.
+    - alert: RegistryCacheVolumeReadOnly
+      expr: sum by (pod) (rate(registry_http_requests_total{code="503"}[5m])) > 0
+      for: 5m
+      labels:
+        severity: warning
+        type: shoot
+        visibility: owner
+      annotations:
+        summary: Registry cache volume is not writable.
+        description: The registry of pod {{ $labels.pod }} answers requests with 503, which its health middleware does once the periodic storagedriver health check fails, typically because the filesystem was remounted read-only after I/O errors. New content is not cached and garbage collection fails. Delete the pod to remount the volume; if it stays read-only, check the health of the underlying disk and recreate the PersistentVolumeClaim.
+    - alert: RegistryCacheContainerOOMKilled
+      expr: kube_pod_container_status_last_terminated_reason{reason="OOMKilled", pod=~"registry-.+", container="registry-cache"} == 1
+      for: 5m
+      labels:
+        severity: warning
+        type: shoot
+        visibility: owner
+      annotations:
+        summary: Registry cache container was OOMKilled.
+        description: The registry cache container of pod {{ $labels.pod }} was killed because it ran out of memory. The inmemory blob descriptor cache grows with the cached content; consider raising the memory limits of the cache.
+`
+
+var scrapeConfigTemplate *template.Template
+
+func init() {
+	scrapeConfigTemplate = template.Must(template.New("").
+		Parse(`- job_name: registry-cache
+{{- if .HonorTimestamps }}
+  honor_timestamps: {{ .HonorTimestamps }}
+{{- end }}
+{{- if .ScrapeTimeout }}
+  scrape_timeout: {{ .ScrapeTimeout }}
+{{- end }}
+{{- if .MetricsAuthSecretName }}
+  authorization:
+    type: Bearer
+    credentials_file: /etc/prometheus/secrets/{{ .MetricsAuthSecretName }}/token
+{{- end }}
+  kubernetes_sd_configs:
+  - role: pod
+    namespaces:
+      names: [{{ .CacheNamespace }}]
+  relabel_configs:
+  - source_labels: [__meta_kubernetes_pod_label_upstream_host]
+    action: keep
+    regex: .+
+  - source_labels: [__meta_kubernetes_pod_container_port_name]
+    action: keep
+    regex: ` + registryCacheDebugPortName + `
+  - source_labels: [__meta_kubernetes_pod_label_upstream_host]
+    target_label: upstream_host
+  - source_labels: [__meta_kubernetes_pod_label_cache_group]
+    target_label: group
+  - source_labels: [__meta_kubernetes_pod_name]
+    target_label: pod
+  - source_labels: [__meta_kubernetes_pod_node_name]
+    target_label: node
+  - target_label: shoot_name
+    replacement: {{ .ShootName }}
+  - target_label: region
+    replacement: {{ .Region }}
+  - target_label: provider
+    replacement: {{ .Provider }}
+  metric_relabel_configs:
+  - source_labels: [__name__]
+    action: keep
+    regex: registry_.+
+{{- range .DropLabels }}
+  - action: labeldrop
+    regex: {{ . }}
+{{- end }}
+`))
+}
+
+// monitoringConfig holds the extension context rendered into the scrape config so that the
+// scraped metrics can be attributed to a shoot in multi-tenant dashboards.
+type monitoringConfig struct {
+	ShootName string
+	Region    string
+	Provider  string
+
+	// DropLabels are label name regexes dropped from the scraped metrics before ingestion to
+	// limit their cardinality. If empty, all labels are kept.
+	DropLabels []string
+
+	// CacheNamespace is the shoot namespace the cache pods are scraped from. If empty, the
+	// default cache namespace is used.
+	CacheNamespace string
+
+	// HonorTimestamps is the rendered honor_timestamps setting of the scrape config ("true" or
+	// "false"). Disabling it stamps the samples with the scrape time, so that slow scrapes
+	// through the apiserver proxy do not leave gaps and trend expressions like the
+	// predict_linear based volume predictions see evenly spaced samples. If empty, the
+	// Prometheus default (true) applies.
+	HonorTimestamps string
+
+	// ScrapeTimeout is the rendered per-scrape timeout of the scrape config. If empty, the
+	// global Prometheus scrape timeout applies.
+	ScrapeTimeout string
+
+	// MetricsAuthSecretName is the name of the Secret in the monitoring namespace whose "token"
+	// key is presented as bearer token when scraping the metrics endpoint. If empty, the
+	// endpoint is scraped without authentication. The token is referenced via credentials_file
+	// and never embedded into the scrape config, so Prometheus re-reads it on every scrape and
+	// rotations of the Secret take effect without a restart and without a scrape gap.
+	MetricsAuthSecretName string
+
+	// AlertLabels are additional labels merged into the labels of the generated alerts. A
+	// configured label replaces a hardcoded label of the same name; the labels required for the
+	// Gardener federation are protected by the configuration validation.
+	AlertLabels map[string]string
+
+	// AlertRetention overrides the "for" clause and adds a "keep_firing_for" clause of the
+	// generated alerts per alert name.
+	AlertRetention map[string]config.AlertRetention
+
+	// LongTermUpstreams are the (sanitized) upstream hosts of the caches that opted into the
+	// hourly downsampled recording rules, see longTermRecordingRules.
+	LongTermUpstreams []string
+}
+
+// alertingRules renders the alerting and recording rules of the registry caches with all
+// configured adjustments applied.
+func (m *monitoringConfig) alertingRules() string {
+	return alertingRulesWithRetention(alertingRulesWithLabels(m.AlertLabels), m.AlertRetention) + longTermRecordingRules(m.LongTermUpstreams)
+}
+
+// longTermRecordingRules renders an additional rule group downsampling the pulled bytes of the
+// given upstreams to hourly resolution. The ":1h" series is federated by the garden Prometheus
+// and kept beyond the retention of the raw series, so capacity can be trended over months.
+func longTermRecordingRules(upstreams []string) string {
+	if len(upstreams) == 0 {
+		return ""
+	}
+
+	var rules strings.Builder
+	rules.WriteString("  - name: registry-cache.longterm.rules\n    rules:\n")
+	for _, upstream := range upstreams {
+		rules.WriteString("    - record: shoot:registry_cache_pulled_bytes:1h\n")
+		rules.WriteString(fmt.Sprintf("      expr: sum by (upstream_host) (increase(registry_http_response_size_bytes_sum{handler=\"blob\",upstream_host=%q}[1h]))\n", upstream))
+	}
+
+	return rules.String()
+}
+
+// alertingRulesWithLabels renders the alerting rules with the given labels merged into the
+// labels of every alert.
+func alertingRulesWithLabels(alertLabels map[string]string) string {
+	if len(alertLabels) == 0 {
+		return alertingRules
+	}
+
+	keys := make([]string, 0, len(alertLabels))
+	for key := range alertLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	rules := alertingRules
+	var extra strings.Builder
+	for _, key := range keys {
+		// drop a hardcoded label of the same name so that the configured value wins without
+		// producing a duplicated map key
+		rules = regexp.MustCompile("(?m)^        "+regexp.QuoteMeta(key)+": .*\n").ReplaceAllString(rules, "")
+		extra.WriteString(fmt.Sprintf("        %s: %s\n", key, alertLabels[key]))
+	}
+
+	return strings.ReplaceAll(rules, "      labels:\n", "      labels:\n"+extra.String())
+}
+
+// alertingRulesWithRetention renders the alerting rules with the configured "for" and
+// "keep_firing_for" clauses applied to the named alerts.
+func alertingRulesWithRetention(rules string, alertRetention map[string]config.AlertRetention) string {
+	if len(alertRetention) == 0 {
+		return rules
+	}
+
+	var (
+		out       strings.Builder
+		retention *config.AlertRetention
+	)
+	for _, line := range strings.SplitAfter(rules, "\n") {
+		if strings.HasPrefix(line, "    - alert: ") {
+			retention = nil
+			if r, ok := alertRetention[strings.TrimSpace(strings.TrimPrefix(line, "    - alert: "))]; ok {
+				retention = &r
+			}
+		}
+		if retention != nil && strings.HasPrefix(line, "      for: ") {
+			if retention.For != nil {
+				line = "      for: " + retention.For.Duration.String() + "\n"
+			}
+			if retention.KeepFiringFor != nil {
+				line += "      keep_firing_for: " + retention.KeepFiringFor.Duration.String() + "\n"
+			}
+		}
+		out.WriteString(line)
+	}
+
+	return out.String()
+}
+
+func (m *monitoringConfig) scrapeConfig() (string, error) {
+	if m.CacheNamespace == "" {
+		m.CacheNamespace = registryCacheNamespaceName
+	}
+
+	var buf bytes.Buffer
+	if err := scrapeConfigTemplate.Execute(&buf, m); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// monitoringNamespace returns the namespace the monitoring objects are deployed to. It defaults
+// to the control plane namespace of the shoot and can be overridden in the extension
+// configuration for setups where the shoot Prometheus lives elsewhere.
+func (a *actuator) monitoringNamespace(namespace string) string {
+	if a.config.Monitoring != nil && a.config.Monitoring.Namespace != nil {
+		return *a.config.Monitoring.Namespace
+	}
+
+	return namespace
+}
+
+// exportMonitoringConfig writes the generated monitoring objects as files into the configured
+// directory, for teams that manage their monitoring via GitOps instead of having the objects
+// applied to the cluster.
+func exportMonitoringConfig(dir string, files map[string]string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("unable to create monitoring export directory: %w", err)
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			return fmt.Errorf("unable to write monitoring file %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// deployMonitoringConfig creates or updates the monitoring ConfigMap for the registry caches in
+// the monitoring namespace.
+func (a *actuator) deployMonitoringConfig(ctx context.Context, m *monitoringConfig, namespace string) error {
+	scrapeConfig, err := m.scrapeConfig()
+	if err != nil {
+		return fmt.Errorf("unable to template scrape config: %w", err)
+	}
+
+	if a.config.Monitoring != nil && a.config.Monitoring.ExportDir != nil {
+		return exportMonitoringConfig(*a.config.Monitoring.ExportDir, map[string]string{
+			monitoringScrapeConfigKey + ".yaml":  scrapeConfig,
+			monitoringAlertingRulesKey + ".yaml": m.alertingRules(),
+		})
+	}
+
+	monitoringNamespace := a.monitoringNamespace(namespace)
+	if monitoringNamespace != namespace {
+		if err := a.client.Get(ctx, client.ObjectKey{Name: monitoringNamespace}, &corev1.Namespace{}); err != nil {
+			return fmt.Errorf("configured monitoring namespace %q cannot be read: %w", monitoringNamespace, err)
+		}
+	}
+
+	if m.MetricsAuthSecretName != "" {
+		// only verify that the referenced token exists; the token value itself is read by
+		// Prometheus from the mounted Secret on every scrape, so a rotated token is picked up
+		// without redeploying the monitoring config
+		tokenSecret := &corev1.Secret{}
+		if err := a.client.Get(ctx, client.ObjectKey{Name: m.MetricsAuthSecretName, Namespace: monitoringNamespace}, tokenSecret); err != nil {
+			return fmt.Errorf("configured metrics auth secret %q cannot be read: %w", m.MetricsAuthSecretName, err)
+		}
+		if len(tokenSecret.Data["token"]) == 0 {
+			return fmt.Errorf("configured metrics auth secret %q does not contain a \"token\" key", m.MetricsAuthSecretName)
+		}
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      monitoringConfigMapName,
+			Namespace: monitoringNamespace,
+		},
+	}
+
+	return reconcileWithRetry(ctx, func(ctx context.Context) error {
+		_, err := controllerutils.GetAndCreateOrMergePatch(ctx, a.client, configMap, func() error {
+			metav1.SetMetaDataLabel(&configMap.ObjectMeta, "extensions.gardener.cloud/configuration", "monitoring")
+			configMap.Data = map[string]string{
+				monitoringScrapeConfigKey:  scrapeConfig,
+				monitoringAlertingRulesKey: m.alertingRules(),
+			}
+			return nil
+		})
+		return err
+	})
+}
@@ -0,0 +1,336 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
+)
+
+// TODO: migrate to ginkgo test
+func Test_monitoringConfig_scrapeConfig(t *testing.T) {
+	m := &monitoringConfig{
+		ShootName: "foo",
+		Region:    "eu-west-1",
+		Provider:  "aws",
+	}
+
+	got, err := m.scrapeConfig()
+	if err != nil {
+		t.Fatalf("monitoringConfig.scrapeConfig() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"- target_label: shoot_name\n    replacement: foo",
+		"- target_label: region\n    replacement: eu-west-1",
+		"- target_label: provider\n    replacement: aws",
+		"regex: registry_.+",
+		"- source_labels: [__meta_kubernetes_pod_label_cache_group]\n    target_label: group",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("monitoringConfig.scrapeConfig() = %q, missing %q", got, want)
+		}
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_monitoringConfig_scrapeConfigHonorTimestamps(t *testing.T) {
+	m := &monitoringConfig{ShootName: "foo"}
+
+	got, err := m.scrapeConfig()
+	if err != nil {
+		t.Fatalf("monitoringConfig.scrapeConfig() error = %v", err)
+	}
+	if strings.Contains(got, "honor_timestamps") || strings.Contains(got, "scrape_timeout") {
+		t.Errorf("expected the Prometheus defaults without tuning, got %q", got)
+	}
+
+	m.HonorTimestamps = "false"
+	m.ScrapeTimeout = "30s"
+	got, err = m.scrapeConfig()
+	if err != nil {
+		t.Fatalf("monitoringConfig.scrapeConfig() error = %v", err)
+	}
+	for _, want := range []string{
+		"honor_timestamps: false",
+		"scrape_timeout: 30s",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("monitoringConfig.scrapeConfig() = %q, missing %q", got, want)
+		}
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_monitoringConfig_scrapeConfigDropLabels(t *testing.T) {
+	m := &monitoringConfig{ShootName: "foo"}
+
+	got, err := m.scrapeConfig()
+	if err != nil {
+		t.Fatalf("monitoringConfig.scrapeConfig() error = %v", err)
+	}
+	if strings.Contains(got, "labeldrop") {
+		t.Errorf("expected no labeldrop rules by default, got %q", got)
+	}
+
+	m.DropLabels = []string{"repository", "tag_.*"}
+	got, err = m.scrapeConfig()
+	if err != nil {
+		t.Fatalf("monitoringConfig.scrapeConfig() error = %v", err)
+	}
+	for _, want := range []string{
+		"- action: labeldrop\n    regex: repository",
+		"- action: labeldrop\n    regex: tag_.*",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("monitoringConfig.scrapeConfig() = %q, missing %q", got, want)
+		}
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_actuator_deployMonitoringConfigToConfiguredNamespace(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "monitoring"}},
+	).Build()
+
+	a := &actuator{
+		client: c,
+		config: config.Configuration{
+			Monitoring: &config.MonitoringConfig{Namespace: pointer.String("monitoring")},
+		},
+	}
+
+	if err := a.deployMonitoringConfig(context.Background(), &monitoringConfig{ShootName: "foo"}, "shoot--foo--bar"); err != nil {
+		t.Fatalf("actuator.deployMonitoringConfig() error = %v", err)
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "monitoring", Name: monitoringConfigMapName}, configMap); err != nil {
+		t.Fatalf("expected monitoring ConfigMap in namespace monitoring: %v", err)
+	}
+	if _, ok := configMap.Data[monitoringScrapeConfigKey]; !ok {
+		t.Errorf("expected monitoring ConfigMap to contain key %q, got %v", monitoringScrapeConfigKey, configMap.Data)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_alertingRules(t *testing.T) {
+	if !strings.Contains(alertingRules, "record: registry_cache:serving_while_upstream_unreachable") {
+		t.Error("expected a recording rule for serving while the upstream is unreachable")
+	}
+	if !strings.Contains(alertingRules, `and on (upstream_host) (sum by (upstream_host) (rate(registry_http_requests_total{code=~"5.."}[5m])) > 0)`) {
+		t.Error("expected the recording rule to correlate served requests with failing ones")
+	}
+	if !strings.Contains(alertingRules, "alert: RegistryCacheServingStaleContent") {
+		t.Error("expected the RegistryCacheServingStaleContent alert")
+	}
+	if !strings.Contains(alertingRules, "severity: info") {
+		t.Error("expected the alert to be informational")
+	}
+	if !strings.Contains(alertingRules, "alert: RegistryCacheVolumeReadOnly") {
+		t.Error("expected the RegistryCacheVolumeReadOnly alert")
+	}
+	if !strings.Contains(alertingRules, `expr: sum by (pod) (rate(registry_http_requests_total{code="503"}[5m])) > 0`) {
+		t.Error("expected the read-only alert to fire on the 503s of the health middleware")
+	}
+	if !strings.Contains(alertingRules, "alert: RegistryCacheContainerOOMKilled") {
+		t.Error("expected the RegistryCacheContainerOOMKilled alert")
+	}
+	if !strings.Contains(alertingRules, `kube_pod_container_status_last_terminated_reason{reason="OOMKilled", pod=~"registry-.+", container="registry-cache"}`) {
+		t.Error("expected the OOMKilled alert to match the last terminated reason of registry-cache pods")
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_monitoringConfig_scrapeConfigMetricsAuth(t *testing.T) {
+	m := &monitoringConfig{ShootName: "foo", Region: "eu-west-1", Provider: "aws"}
+
+	scrapeConfig, err := m.scrapeConfig()
+	if err != nil {
+		t.Fatalf("scrapeConfig() error = %v", err)
+	}
+	if strings.Contains(scrapeConfig, "authorization:") {
+		t.Errorf("expected no authorization block without a configured metrics auth secret, got:\n%s", scrapeConfig)
+	}
+
+	m.MetricsAuthSecretName = "registry-cache-metrics-auth"
+	if scrapeConfig, err = m.scrapeConfig(); err != nil {
+		t.Fatalf("scrapeConfig() error = %v", err)
+	}
+	if !strings.Contains(scrapeConfig, "type: Bearer") || !strings.Contains(scrapeConfig, "credentials_file: /etc/prometheus/secrets/registry-cache-metrics-auth/token") {
+		t.Errorf("expected a bearer authorization block, got:\n%s", scrapeConfig)
+	}
+	if !strings.Contains(scrapeConfig, "job_name: registry-cache") {
+		t.Errorf("expected the scrape job to still be rendered, got:\n%s", scrapeConfig)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_actuator_deployMonitoringConfigTokenRotation(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry-cache-metrics-auth", Namespace: "shoot--foo--bar"},
+		Data:       map[string][]byte{"token": []byte("initial-token")},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tokenSecret).Build()
+
+	a := &actuator{
+		client: c,
+		config: config.Configuration{
+			Monitoring: &config.MonitoringConfig{MetricsAuthSecretName: pointer.String("registry-cache-metrics-auth")},
+		},
+	}
+	m := &monitoringConfig{ShootName: "foo", MetricsAuthSecretName: "registry-cache-metrics-auth"}
+
+	if err := a.deployMonitoringConfig(context.Background(), m, "shoot--foo--bar"); err != nil {
+		t.Fatalf("actuator.deployMonitoringConfig() error = %v", err)
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "shoot--foo--bar", Name: monitoringConfigMapName}, configMap); err != nil {
+		t.Fatalf("expected monitoring ConfigMap: %v", err)
+	}
+	scrapeConfigBeforeRotation := configMap.Data[monitoringScrapeConfigKey]
+	if strings.Contains(scrapeConfigBeforeRotation, "initial-token") {
+		t.Fatal("scrape config must reference the token via credentials_file instead of embedding it")
+	}
+
+	// rotate the token; the already deployed scrape config must remain valid as is because
+	// Prometheus re-reads the credentials file on every scrape
+	tokenSecret.Data["token"] = []byte("rotated-token")
+	if err := c.Update(context.Background(), tokenSecret); err != nil {
+		t.Fatalf("failed to rotate token secret: %v", err)
+	}
+	if err := a.deployMonitoringConfig(context.Background(), m, "shoot--foo--bar"); err != nil {
+		t.Fatalf("actuator.deployMonitoringConfig() after rotation error = %v", err)
+	}
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "shoot--foo--bar", Name: monitoringConfigMapName}, configMap); err != nil {
+		t.Fatalf("expected monitoring ConfigMap after rotation: %v", err)
+	}
+	if configMap.Data[monitoringScrapeConfigKey] != scrapeConfigBeforeRotation {
+		t.Error("scrape config changed on token rotation; rotations must not require a config reload")
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_alertingRulesWithLabels(t *testing.T) {
+	if got := alertingRulesWithLabels(nil); got != alertingRules {
+		t.Error("expected the unmodified rules without configured labels")
+	}
+
+	got := alertingRulesWithLabels(map[string]string{
+		"team":     "platform",
+		"severity": "critical",
+	})
+	if !strings.Contains(got, "        team: platform\n") {
+		t.Errorf("expected the team label to be added to the alerts:\n%s", got)
+	}
+	if !strings.Contains(got, "        severity: critical\n") {
+		t.Errorf("expected the severity label to be overridden:\n%s", got)
+	}
+	if strings.Contains(got, "severity: info") || strings.Contains(got, "severity: warning") {
+		t.Errorf("expected the hardcoded severities to be replaced:\n%s", got)
+	}
+	if !strings.Contains(got, "        type: shoot\n") || !strings.Contains(got, "        visibility: owner\n") {
+		t.Errorf("expected the federation labels to stay intact:\n%s", got)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_actuator_deployMonitoringConfigExportDir(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	exportDir := t.TempDir()
+	a := &actuator{
+		client: c,
+		config: config.Configuration{
+			Monitoring: &config.MonitoringConfig{ExportDir: pointer.String(exportDir)},
+		},
+	}
+
+	if err := a.deployMonitoringConfig(context.Background(), &monitoringConfig{ShootName: "foo"}, "shoot--foo--bar"); err != nil {
+		t.Fatalf("actuator.deployMonitoringConfig() error = %v", err)
+	}
+
+	scrapeConfig, err := os.ReadFile(filepath.Join(exportDir, monitoringScrapeConfigKey+".yaml"))
+	if err != nil {
+		t.Fatalf("expected the scrape config to be exported: %v", err)
+	}
+	if !strings.Contains(string(scrapeConfig), "job_name: registry-cache") {
+		t.Errorf("expected the exported scrape config to contain the scrape job, got:\n%s", scrapeConfig)
+	}
+
+	alertingRulesFile, err := os.ReadFile(filepath.Join(exportDir, monitoringAlertingRulesKey+".yaml"))
+	if err != nil {
+		t.Fatalf("expected the alerting rules to be exported: %v", err)
+	}
+	if string(alertingRulesFile) != alertingRules {
+		t.Error("expected the exported alerting rules to match the generated ones")
+	}
+
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "shoot--foo--bar", Name: monitoringConfigMapName}, &corev1.ConfigMap{}); err == nil {
+		t.Error("expected no monitoring ConfigMap to be created in export mode")
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_alertingRulesWithRetention(t *testing.T) {
+	if got := alertingRulesWithRetention(alertingRules, nil); got != alertingRules {
+		t.Error("expected the unmodified rules without configured retention")
+	}
+
+	got := alertingRulesWithRetention(alertingRules, map[string]config.AlertRetention{
+		"RegistryCacheServingStaleContent": {
+			For:           &metav1.Duration{Duration: 30 * time.Minute},
+			KeepFiringFor: &metav1.Duration{Duration: 10 * time.Minute},
+		},
+	})
+	if !strings.Contains(got, "      for: 30m0s\n      keep_firing_for: 10m0s\n") {
+		t.Errorf("expected the configured retention on the stale content alert:\n%s", got)
+	}
+	if !strings.Contains(got, "      for: 5m\n") {
+		t.Errorf("expected the OOMKilled alert to keep its hardcoded for clause:\n%s", got)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_longTermRecordingRules(t *testing.T) {
+	if got := longTermRecordingRules(nil); got != "" {
+		t.Errorf("expected no rules without opted-in upstreams, got:\n%s", got)
+	}
+
+	m := &monitoringConfig{LongTermUpstreams: []string{"docker.io", "quay.io"}}
+	got := m.alertingRules()
+	if !strings.Contains(got, "  - name: registry-cache.longterm.rules\n") {
+		t.Errorf("expected a dedicated long-term rule group:\n%s", got)
+	}
+	if !strings.Contains(got, "    - record: shoot:registry_cache_pulled_bytes:1h\n      expr: sum by (upstream_host) (increase(registry_http_response_size_bytes_sum{handler=\"blob\",upstream_host=\"docker.io\"}[1h]))\n") {
+		t.Errorf("expected an hourly downsampling rule per opted-in upstream:\n%s", got)
+	}
+	if !strings.Contains(got, "upstream_host=\"quay.io\"") {
+		t.Errorf("expected a rule per opted-in upstream:\n%s", got)
+	}
+	if !strings.Contains(got, "    - alert: RegistryCacheServingStaleContent\n") {
+		t.Errorf("expected the alerting rules to be kept:\n%s", got)
+	}
+}
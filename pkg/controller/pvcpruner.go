@@ -0,0 +1,130 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha1"
+)
+
+const (
+	// orphanedSinceAnnotation records when a cache volume claim was first observed without a
+	// configured cache. It starts the pruning grace period.
+	orphanedSinceAnnotation = "registry-cache.extensions.gardener.cloud/orphaned-since"
+	// defaultOrphanedPVCTTL is the grace period an orphaned cache volume claim is kept if no
+	// TTL is configured.
+	defaultOrphanedPVCTTL = 72 * time.Hour
+)
+
+func (a *actuator) orphanedPVCTTL() time.Duration {
+	if a.config.PVCPruning != nil && a.config.PVCPruning.TTL != nil {
+		return a.config.PVCPruning.TTL.Duration
+	}
+
+	return defaultOrphanedPVCTTL
+}
+
+// pruneOrphanedPVCs deletes cache volume claims whose cache has been removed from the registry
+// configuration once they have been orphaned for longer than the configured TTL. A claim is
+// marked with an annotation when first observed as orphaned, so the grace period starts from
+// observation; the mark is removed again should the cache be re-added. An event is emitted for
+// every deleted claim.
+func (a *actuator) pruneOrphanedPVCs(ctx context.Context, log logr.Logger, shootClient client.Client, registryConfig *v1alpha1.RegistryConfig) error {
+	now := a.now()
+
+	configured := map[string]struct{}{}
+	for _, cache := range registryConfig.Caches {
+		configured[cache.Upstream] = struct{}{}
+	}
+
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := shootClient.List(ctx, pvcs, client.InNamespace(registryCacheNamespace(registryConfig))); err != nil {
+		return fmt.Errorf("could not read cache volume claims from shoot: %w", err)
+	}
+
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		upstream, ok := pvc.Labels[registryCacheServiceUpstreamLabel]
+		if !ok {
+			continue
+		}
+
+		patch := client.MergeFrom(pvc.DeepCopy())
+		if _, ok := configured[upstream]; ok {
+			if _, marked := pvc.Annotations[orphanedSinceAnnotation]; marked {
+				delete(pvc.Annotations, orphanedSinceAnnotation)
+				if err := shootClient.Patch(ctx, pvc, patch); err != nil {
+					return fmt.Errorf("could not unmark re-added cache volume claim %q: %w", pvc.Name, err)
+				}
+			}
+			continue
+		}
+
+		orphanedSince, err := time.Parse(time.RFC3339, pvc.Annotations[orphanedSinceAnnotation])
+		if err != nil {
+			// not marked yet (or the mark is unreadable); start the grace period now
+			metav1.SetMetaDataAnnotation(&pvc.ObjectMeta, orphanedSinceAnnotation, now.UTC().Format(time.RFC3339))
+			if err := shootClient.Patch(ctx, pvc, patch); err != nil {
+				return fmt.Errorf("could not mark orphaned cache volume claim %q: %w", pvc.Name, err)
+			}
+			continue
+		}
+
+		if now.Sub(orphanedSince) < a.orphanedPVCTTL() {
+			continue
+		}
+
+		event := &corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "registry-cache-pvc-pruning-",
+				Namespace:    pvc.Namespace,
+			},
+			InvolvedObject: corev1.ObjectReference{
+				APIVersion: "v1",
+				Kind:       "PersistentVolumeClaim",
+				Name:       pvc.Name,
+				Namespace:  pvc.Namespace,
+				UID:        pvc.UID,
+			},
+			Reason:         "OrphanedVolumeClaimDeleted",
+			Message:        fmt.Sprintf("Deleting volume claim of removed registry cache for upstream %s after a grace period of %s", upstream, a.orphanedPVCTTL()),
+			Type:           corev1.EventTypeNormal,
+			Source:         corev1.EventSource{Component: ControllerName},
+			FirstTimestamp: metav1.NewTime(now),
+			LastTimestamp:  metav1.NewTime(now),
+			Count:          1,
+		}
+		if err := shootClient.Create(ctx, event); err != nil {
+			// the event is informational only, its failure must not keep the claim around
+			log.Error(err, "could not emit event for pruned cache volume claim", "name", pvc.Name)
+		}
+
+		log.Info("deleting orphaned cache volume claim", "name", pvc.Name, "upstream", upstream)
+		if err := shootClient.Delete(ctx, pvc); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("could not delete orphaned cache volume claim %q: %w", pvc.Name, err)
+		}
+	}
+
+	return nil
+}
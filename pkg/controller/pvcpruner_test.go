@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha1"
+)
+
+// TODO: migrate to ginkgo test
+func Test_actuator_pruneOrphanedPVCs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+
+	now := time.Date(2022, time.November, 1, 12, 0, 0, 0, time.UTC)
+	cachePVC := func(name, upstream string, orphanedSince *time.Time) *corev1.PersistentVolumeClaim {
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: registryCacheNamespaceName,
+				Labels:    map[string]string{registryCacheServiceUpstreamLabel: upstream},
+			},
+		}
+		if orphanedSince != nil {
+			pvc.Annotations = map[string]string{orphanedSinceAnnotation: orphanedSince.Format(time.RFC3339)}
+		}
+		return pvc
+	}
+
+	pastTTL := now.Add(-100 * time.Hour)
+	withinTTL := now.Add(-time.Hour)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		cachePVC("cache-volume-registry-docker-io-0", "docker.io", &pastTTL),
+		cachePVC("cache-volume-registry-ghcr-io-0", "ghcr.io", &pastTTL),
+		cachePVC("cache-volume-registry-quay-io-0", "quay.io", &withinTTL),
+		cachePVC("cache-volume-registry-gcr-io-0", "gcr.io", nil),
+	).Build()
+
+	a := &actuator{now: func() time.Time { return now }}
+	registryConfig := &v1alpha1.RegistryConfig{
+		Caches: []v1alpha1.RegistryCache{{Upstream: "docker.io"}},
+	}
+
+	if err := a.pruneOrphanedPVCs(context.Background(), logr.Discard(), c, registryConfig); err != nil {
+		t.Fatalf("actuator.pruneOrphanedPVCs() error = %v", err)
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+
+	// the claim of the still configured cache is kept and unmarked
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: registryCacheNamespaceName, Name: "cache-volume-registry-docker-io-0"}, pvc); err != nil {
+		t.Fatalf("expected claim of configured cache to be kept: %v", err)
+	}
+	if _, ok := pvc.Annotations[orphanedSinceAnnotation]; ok {
+		t.Error("expected the orphaned-since mark of the configured cache to be removed")
+	}
+
+	// the claim orphaned for longer than the TTL is deleted and an event is emitted
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: registryCacheNamespaceName, Name: "cache-volume-registry-ghcr-io-0"}, pvc); !apierrors.IsNotFound(err) {
+		t.Errorf("expected claim past the TTL to be deleted, got err = %v", err)
+	}
+	events := &corev1.EventList{}
+	if err := c.List(context.Background(), events, client.InNamespace(registryCacheNamespaceName)); err != nil {
+		t.Fatalf("could not list events: %v", err)
+	}
+	if len(events.Items) != 1 || events.Items[0].InvolvedObject.Name != "cache-volume-registry-ghcr-io-0" {
+		t.Errorf("expected exactly one event for the deleted claim, got %v", events.Items)
+	}
+
+	// the claim orphaned for less than the TTL is kept
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: registryCacheNamespaceName, Name: "cache-volume-registry-quay-io-0"}, pvc); err != nil {
+		t.Errorf("expected claim within the TTL to be kept: %v", err)
+	}
+
+	// an unmarked orphaned claim is marked so its grace period starts now
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: registryCacheNamespaceName, Name: "cache-volume-registry-gcr-io-0"}, pvc); err != nil {
+		t.Fatalf("expected unmarked orphaned claim to be kept: %v", err)
+	}
+	if got := pvc.Annotations[orphanedSinceAnnotation]; got != now.Format(time.RFC3339) {
+		t.Errorf("orphaned-since mark = %q, want %q", got, now.Format(time.RFC3339))
+	}
+}
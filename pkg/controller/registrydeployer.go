@@ -16,11 +16,16 @@ package controller
 
 import (
 	"fmt"
+	"hash/fnv"
+	"sort"
 	"strconv"
 	"strings"
 
+	resourcesv1alpha1 "github.com/gardener/gardener/pkg/apis/resources/v1alpha1"
 	"github.com/gardener/gardener/pkg/utils/imagevector"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -28,6 +33,9 @@ import (
 	"k8s.io/utils/pointer"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha1"
 )
 
 type registryCache struct {
@@ -35,27 +43,168 @@ type registryCache struct {
 	Namespace string
 	Labels    map[string]string
 
-	Upstream                 string
-	VolumeSize               resource.Quantity
+	Upstream   string
+	Insecure   bool
+	PathPrefix string
+	VolumeSize resource.Quantity
+	VolumeMode *string
+	// StorageClassName is the StorageClass of the cache volume. Nil keeps the cluster
+	// default. It is either configured explicitly or selected by the storage tiering policy.
+	StorageClassName *string
+	// MigrateFromClaim names a staging volume claim whose content is restored into the cache
+	// volume on startup during a storage tier migration, see reconcileStorageTier.
+	MigrateFromClaim         string
 	GarbageCollectionEnabled bool
+	GarbageCollection        *v1alpha1.GarbageCollection
+	// VolumeBelowMinFree reports that the free space on the cache volume dropped below the
+	// configured GarbageCollection.MinFreeBytes. It is set by the actuator from the kubelet
+	// volume stats and switches the registry into the read-only maintenance mode, so that
+	// already-cached content is still served while cache misses are no longer stored.
+	VolumeBelowMinFree bool
+	// UploadPurging tunes the purging of stale in-progress uploads. Unset fields keep the
+	// distribution defaults.
+	UploadPurging       *v1alpha1.UploadPurging
+	CATrustStore        *v1alpha1.CATrustStore
+	UpdateStrategy      *v1alpha1.UpdateStrategy
+	Mode                string
+	HostPort            int32
+	ServiceAccountName  string
+	DNSPolicy           *string
+	DNSConfig           *v1.PodDNSConfig
+	ImagePullPolicy     *string
+	FSGroup             *int64
+	FSGroupChangePolicy *string
+	HTTPHost            string
+	LivenessProbe       *v1alpha1.LivenessProbe
+	StartupProbe        *v1alpha1.StartupProbe
+	ExtraConfig         string
+	PreferredAffinity   *v1alpha1.PreferredAffinity
+	NodeAffinity        *v1.NodeAffinity
+	FallbackToUpstream  bool
+	SmokeTest           *v1alpha1.SmokeTest
+	// EvictRepository is a repository whose cached content is deleted by a one-off eviction
+	// Job, see ensureEvictionJob. Set by the actuator from the eviction annotation on the
+	// Extension resource.
+	EvictRepository string
+	// ManualGC generates a one-off Job starting an immediate garbage collection run. Set by
+	// the actuator from the garbage collection annotation on the Extension, see
+	// garbageCollectAnnotation
+	ManualGC          bool
+	Service           *v1alpha1.Service
+	Debug             *v1alpha1.Debug
+	CompressionPolicy *string
+	Storage           *v1alpha1.Storage
+	// Autoscaling configures a HorizontalPodAutoscaler for the cache, see ensureAutoscaler;
+	// admission guarantees it is only set in StatefulSet mode
+	Autoscaling *v1alpha1.Autoscaling
+	// Group is the purpose group of the cache propagated as pod label, see
+	// registryCacheGroupLabel
+	Group string
+	// Schema1Compatibility toggles the deprecated Docker schema 1 manifest support; nil
+	// keeps the registry default
+	Schema1Compatibility *bool
+	// HTTP2Enabled enables/disables HTTP/2 on the cache server; nil keeps the registry
+	// default (enabled)
+	HTTP2Enabled        *bool
+	ResourceProfile     *string
+	Resources           *v1.ResourceRequirements
+	PodManagementPolicy *string
+	UpstreamReadiness   bool
+	// UpstreamIPs are IPs the upstream registry host was resolved to at reconcile time. When
+	// set, they are pinned via hostAliases of the cache pod so that fetches from the upstream
+	// do not depend on DNS being available.
+	UpstreamIPs []string
 
 	RegistryImage *imagevector.Image
 }
 
 const (
-	registryCacheNamespaceName = "registry-cache"
-	registryCacheInternalName  = "registry-cache"
-	registryCacheVolumeName    = "cache-volume"
-	registryVolumeMountPath    = "/var/lib/registry"
+	registryCacheNamespaceName      = "registry-cache"
+	registryCacheInternalName       = "registry-cache"
+	registryCacheServiceAccountName = "registry-cache"
+	registryCacheVolumeName         = "cache-volume"
+	registryVolumeMountPath         = "/var/lib/registry"
+
+	environmentVariableNameRegistryURL        = "REGISTRY_PROXY_REMOTEURL"
+	environmentVariableNameRegistryDelete     = "REGISTRY_STORAGE_DELETE_ENABLED"
+	environmentVariableNameRegistryHTTPSecret = "REGISTRY_HTTP_SECRET"
+	environmentVariableNameRegistryHTTPHost   = "REGISTRY_HTTP_HOST"
+
+	environmentVariableNameRegistryGCReadOnly = "REGISTRY_STORAGE_MAINTENANCE_READONLY_ENABLED"
+
+	environmentVariableNameRegistryUploadPurgingEnabled  = "REGISTRY_STORAGE_MAINTENANCE_UPLOADPURGING_ENABLED"
+	environmentVariableNameRegistryUploadPurgingAge      = "REGISTRY_STORAGE_MAINTENANCE_UPLOADPURGING_AGE"
+	environmentVariableNameRegistryUploadPurgingInterval = "REGISTRY_STORAGE_MAINTENANCE_UPLOADPURGING_INTERVAL"
+	environmentVariableNameRegistryUploadPurgingDryRun   = "REGISTRY_STORAGE_MAINTENANCE_UPLOADPURGING_DRYRUN"
+
+	// environmentVariableNameRegistryDriverPrefix prefixes the filesystem storage driver
+	// parameters, e.g. REGISTRY_STORAGE_FILESYSTEM_MAXTHREADS.
+	environmentVariableNameRegistryDriverPrefix = "REGISTRY_STORAGE_FILESYSTEM_"
 
-	environmentVarialbleNameRegistryURL    = "REGISTRY_PROXY_REMOTEURL"
-	environmentVarialbleNameRegistryDelete = "REGISTRY_STORAGE_DELETE_ENABLED"
+	environmentVariableNameRegistrySchema1Enabled         = "REGISTRY_COMPATIBILITY_SCHEMA1_ENABLED"
+	environmentVariableNameRegistryHTTP2Disabled          = "REGISTRY_HTTP_HTTP2_DISABLED"
+	environmentVariableNameRegistryHealthStorageEnabled   = "REGISTRY_HEALTH_STORAGEDRIVER_ENABLED"
+	environmentVariableNameRegistryHealthStorageInterval  = "REGISTRY_HEALTH_STORAGEDRIVER_INTERVAL"
+	environmentVariableNameRegistryHealthStorageThreshold = "REGISTRY_HEALTH_STORAGEDRIVER_THRESHOLD"
+	environmentVariableNameRegistryDebugAddr              = "REGISTRY_HTTP_DEBUG_ADDR"
+	environmentVariableNameRegistryDebugPrometheusEnabled = "REGISTRY_HTTP_DEBUG_PROMETHEUS_ENABLED"
+	environmentVariableNameRegistryDebugPprofEnabled      = "REGISTRY_HTTP_DEBUG_PPROF_ENABLED"
+
+	// registryCacheDebugPortName names the container port of the debug endpoint; the scrape
+	// config addresses the metrics target by this name.
+	registryCacheDebugPortName = "debug"
+	// defaultRegistryCacheDebugPort is the port of the debug endpoint unless configured
+	// otherwise.
+	defaultRegistryCacheDebugPort int32 = 5001
 
 	registryCacheServiceUpstreamLabel = "upstream-host"
+	// registryCacheGroupLabel carries the configured purpose group of the cache; the scrape
+	// config maps it to the "group" metric label used as dashboard and alert grouping
+	// dimension.
+	registryCacheGroupLabel = "cache-group"
+	// registryCacheServiceUpstreamAnnotation carries the unmodified upstream of a cache
+	// service, including a port if one is configured, which is not a valid label value.
+	registryCacheServiceUpstreamAnnotation = "upstream-host"
+	// registryCacheServiceFallbackAnnotation records whether the upstream itself is added as a
+	// secondary mirror endpoint. Anything but "false" (including an absent annotation) enables
+	// the fallback.
+	registryCacheServiceFallbackAnnotation = "fallback-to-upstream"
+	// registryCacheServiceInsecureAnnotation records whether the upstream is reached via plain
+	// HTTP, so that the fallback endpoint rendered for containerd uses the matching scheme.
+	registryCacheServiceInsecureAnnotation = "upstream-insecure"
+	// registryCacheServiceHostPortAnnotation records the host port of a cache deployed in the
+	// DaemonSet mode. When set, containerd is pointed to localhost:<hostPort> instead of the
+	// service cluster IP.
+	registryCacheServiceHostPortAnnotation = "host-port"
+
+	// registryCacheHostPathBase is the node directory under which caches deployed in the
+	// DaemonSet mode store their content.
+	registryCacheHostPathBase = "/var/lib/registry-cache"
+
+	hostPathInitContainerName = "prepare-cache-dir"
+
+	compressionReportContainerName = "compression-report"
+
+	tmpVolumeName      = "tmp"
+	tmpVolumeMountPath = "/tmp"
+
+	caTrustStoreVolumeName        = "ca-trust-store"
+	caCertificatesVolumeName      = "ca-certificates"
+	caTrustStoreMountPath         = "/usr/local/share/ca-certificates"
+	caCertificatesMountPath       = "/etc/ssl/certs"
+	caTrustStoreInitContainerName = "update-ca-certificates"
+
+	registryConfigVolumeName = "config"
+	registryConfigMountPath  = "/etc/docker/registry"
+	registryConfigKey        = "config.yml"
 )
 
 func (c *registryCache) Ensure() ([]client.Object, error) {
-	c.Name = strings.Replace(fmt.Sprintf("registry-%s", strings.Split(c.Upstream, ":")[0]), ".", "-", -1)
+	c.Name = cacheObjectName(c.Upstream)
+
+	if c.ServiceAccountName == "" {
+		c.ServiceAccountName = registryCacheServiceAccountName
+	}
 
 	if c.Labels == nil {
 		c.Labels = map[string]string{
@@ -65,18 +214,29 @@ func (c *registryCache) Ensure() ([]client.Object, error) {
 
 	c.Labels[registryCacheServiceUpstreamLabel] = c.Upstream
 
-	upstreamURL := c.Upstream
-	if upstreamURL == "docker.io" {
-		upstreamURL = "registry-1.docker.io"
+	// the group label is kept out of c.Labels: c.Labels feeds the Service selector and the
+	// immutable StatefulSet selector, and the group of a cache may change over its lifetime
+	podLabels := c.Labels
+	if c.Group != "" {
+		podLabels = map[string]string{registryCacheGroupLabel: c.Group}
+		for key, value := range c.Labels {
+			podLabels[key] = value
+		}
 	}
-	upstreamURL = fmt.Sprintf("https://%s", upstreamURL)
+
+	upstreamURL := UpstreamRegistryURL(c.Upstream, c.Insecure) + c.PathPrefix
 
 	var (
 		service = &v1.Service{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      c.Name,
-				Namespace: registryCacheNamespaceName,
+				Namespace: c.Namespace,
 				Labels:    c.Labels,
+				Annotations: map[string]string{
+					registryCacheServiceUpstreamAnnotation: c.Upstream,
+					registryCacheServiceFallbackAnnotation: strconv.FormatBool(c.FallbackToUpstream),
+					registryCacheServiceInsecureAnnotation: strconv.FormatBool(c.Insecure),
+				},
 			},
 			Spec: v1.ServiceSpec{
 				Selector: c.Labels,
@@ -93,7 +253,7 @@ func (c *registryCache) Ensure() ([]client.Object, error) {
 		statefulSet = &appsv1.StatefulSet{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      c.Name,
-				Namespace: registryCacheNamespaceName,
+				Namespace: c.Namespace,
 				Labels:    c.Labels,
 			},
 			Spec: appsv1.StatefulSetSpec{
@@ -104,9 +264,19 @@ func (c *registryCache) Ensure() ([]client.Object, error) {
 				Replicas: pointer.Int32(1),
 				Template: v1.PodTemplateSpec{
 					ObjectMeta: metav1.ObjectMeta{
-						Labels: c.Labels,
+						Labels: podLabels,
 					},
 					Spec: v1.PodSpec{
+						ServiceAccountName: c.ServiceAccountName,
+						// the registry does not talk to the API server
+						AutomountServiceAccountToken: pointer.Bool(false),
+						SecurityContext: &v1.PodSecurityContext{
+							RunAsNonRoot: pointer.Bool(true),
+							RunAsUser:    pointer.Int64(1000),
+							SeccompProfile: &v1.SeccompProfile{
+								Type: v1.SeccompProfileTypeRuntimeDefault,
+							},
+						},
 						Containers: []v1.Container{
 							{
 								Name:            registryCacheInternalName,
@@ -120,13 +290,32 @@ func (c *registryCache) Ensure() ([]client.Object, error) {
 								},
 								Env: []v1.EnvVar{
 									{
-										Name:  environmentVarialbleNameRegistryURL,
+										Name:  environmentVariableNameRegistryURL,
 										Value: upstreamURL,
 									},
 									{
-										Name:  environmentVarialbleNameRegistryDelete,
+										Name:  environmentVariableNameRegistryDelete,
 										Value: strconv.FormatBool(c.GarbageCollectionEnabled),
 									},
+									{
+										// a stable http.secret shared by all replicas, see httpSecret
+										Name: environmentVariableNameRegistryHTTPSecret,
+										ValueFrom: &v1.EnvVarSource{
+											SecretKeyRef: &v1.SecretKeySelector{
+												LocalObjectReference: v1.LocalObjectReference{
+													Name: registryCacheHTTPSecretName,
+												},
+												Key: registryCacheHTTPSecretKey,
+											},
+										},
+									},
+								},
+								SecurityContext: &v1.SecurityContext{
+									AllowPrivilegeEscalation: pointer.Bool(false),
+									ReadOnlyRootFilesystem:   pointer.Bool(true),
+									Capabilities: &v1.Capabilities{
+										Drop: []v1.Capability{"ALL"},
+									},
 								},
 								VolumeMounts: []v1.VolumeMount{
 									{
@@ -134,6 +323,19 @@ func (c *registryCache) Ensure() ([]client.Object, error) {
 										ReadOnly:  false,
 										MountPath: registryVolumeMountPath,
 									},
+									{
+										// the registry needs a writable tmp with a read-only root filesystem
+										Name:      tmpVolumeName,
+										MountPath: tmpVolumeMountPath,
+									},
+								},
+							},
+						},
+						Volumes: []v1.Volume{
+							{
+								Name: tmpVolumeName,
+								VolumeSource: v1.VolumeSource{
+									EmptyDir: &v1.EmptyDirVolumeSource{},
 								},
 							},
 						},
@@ -159,8 +361,1023 @@ func (c *registryCache) Ensure() ([]client.Object, error) {
 		}
 	)
 
-	return []client.Object{
+	if c.VolumeMode != nil {
+		volumeMode := v1.PersistentVolumeMode(*c.VolumeMode)
+		statefulSet.Spec.VolumeClaimTemplates[0].Spec.VolumeMode = &volumeMode
+	}
+
+	if c.StorageClassName != nil {
+		statefulSet.Spec.VolumeClaimTemplates[0].Spec.StorageClassName = c.StorageClassName
+	}
+
+	if c.MigrateFromClaim != "" {
+		// restore the staged content of a storage tier migration into the fresh cache volume;
+		// the marker file keeps the copy from running again on later pod restarts
+		statefulSet.Spec.Template.Spec.Volumes = append(statefulSet.Spec.Template.Spec.Volumes, v1.Volume{
+			Name: tierMigrationVolumeName,
+			VolumeSource: v1.VolumeSource{
+				PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+					ClaimName: c.MigrateFromClaim,
+					ReadOnly:  true,
+				},
+			},
+		})
+		statefulSet.Spec.Template.Spec.InitContainers = append(statefulSet.Spec.Template.Spec.InitContainers, v1.Container{
+			Name:            tierRestoreInitContainerName,
+			Image:           c.RegistryImage.Repository,
+			ImagePullPolicy: v1.PullIfNotPresent,
+			Command: []string{
+				"sh",
+				"-c",
+				"if [ ! -e " + registryVolumeMountPath + "/" + tierRestoredMarker + " ]; then cp -a " + tierMigrationMountPath + "/. " + registryVolumeMountPath + "/ && touch " + registryVolumeMountPath + "/" + tierRestoredMarker + "; fi",
+			},
+			VolumeMounts: []v1.VolumeMount{
+				{
+					Name:      tierMigrationVolumeName,
+					ReadOnly:  true,
+					MountPath: tierMigrationMountPath,
+				},
+				{
+					Name:      registryCacheVolumeName,
+					MountPath: registryVolumeMountPath,
+				},
+			},
+		})
+	}
+
+	if c.PodManagementPolicy != nil {
+		statefulSet.Spec.PodManagementPolicy = appsv1.PodManagementPolicyType(*c.PodManagementPolicy)
+	}
+
+	// explicit resources are the escape hatch for setups none of the named profiles fits
+	if c.Resources != nil {
+		statefulSet.Spec.Template.Spec.Containers[0].Resources = *c.Resources
+	} else if c.ResourceProfile != nil {
+		statefulSet.Spec.Template.Spec.Containers[0].Resources = profileResources(*c.ResourceProfile)
+	}
+
+	if c.Service != nil {
+		if c.Service.Type != nil {
+			service.Spec.Type = v1.ServiceType(*c.Service.Type)
+		}
+		if c.Service.SessionAffinity != nil {
+			service.Spec.SessionAffinity = v1.ServiceAffinity(*c.Service.SessionAffinity)
+			if service.Spec.SessionAffinity == v1.ServiceAffinityClientIP && c.Service.SessionAffinityTimeoutSeconds != nil {
+				service.Spec.SessionAffinityConfig = &v1.SessionAffinityConfig{
+					ClientIP: &v1.ClientIPConfig{TimeoutSeconds: c.Service.SessionAffinityTimeoutSeconds},
+				}
+			}
+		}
+	}
+
+	// the free-space safety valve, see VolumeBelowMinFree: the read-only maintenance mode
+	// rejects writes to the storage, so cache misses are no longer stored while already-cached
+	// content keeps being served
+	if c.VolumeBelowMinFree {
+		registryContainer := &statefulSet.Spec.Template.Spec.Containers[0]
+		registryContainer.Env = append(registryContainer.Env, v1.EnvVar{
+			Name:  environmentVariableNameRegistryGCReadOnly,
+			Value: "true",
+		})
+	}
+
+	if c.GarbageCollectionEnabled && c.GarbageCollection != nil {
+		registryContainer := &statefulSet.Spec.Template.Spec.Containers[0]
+		if c.GarbageCollection.ReadOnly != nil && !c.VolumeBelowMinFree {
+			registryContainer.Env = append(registryContainer.Env, v1.EnvVar{
+				Name:  environmentVariableNameRegistryGCReadOnly,
+				Value: strconv.FormatBool(*c.GarbageCollection.ReadOnly),
+			})
+		}
+	}
+
+	if c.UploadPurging != nil {
+		registryContainer := &statefulSet.Spec.Template.Spec.Containers[0]
+		if c.UploadPurging.Enabled != nil {
+			registryContainer.Env = append(registryContainer.Env, v1.EnvVar{
+				Name:  environmentVariableNameRegistryUploadPurgingEnabled,
+				Value: strconv.FormatBool(*c.UploadPurging.Enabled),
+			})
+		}
+		if c.UploadPurging.Age != nil {
+			registryContainer.Env = append(registryContainer.Env, v1.EnvVar{
+				Name:  environmentVariableNameRegistryUploadPurgingAge,
+				Value: c.UploadPurging.Age.Duration.String(),
+			})
+		}
+		if c.UploadPurging.Interval != nil {
+			registryContainer.Env = append(registryContainer.Env, v1.EnvVar{
+				Name:  environmentVariableNameRegistryUploadPurgingInterval,
+				Value: c.UploadPurging.Interval.Duration.String(),
+			})
+		}
+		if c.UploadPurging.DryRun != nil {
+			registryContainer.Env = append(registryContainer.Env, v1.EnvVar{
+				Name:  environmentVariableNameRegistryUploadPurgingDryRun,
+				Value: strconv.FormatBool(*c.UploadPurging.DryRun),
+			})
+		}
+	}
+
+	// the liveness probe only verifies that the local registry process still answers requests;
+	// it deliberately does not check the upstream to avoid restarts while the upstream is down
+	livenessProbe := &v1.Probe{
+		ProbeHandler: v1.ProbeHandler{
+			HTTPGet: &v1.HTTPGetAction{
+				Path: "/v2/",
+				Port: intstr.FromString(registryCacheInternalName),
+			},
+		},
+		InitialDelaySeconds: 5,
+		PeriodSeconds:       10,
+		TimeoutSeconds:      2,
+		FailureThreshold:    6,
+	}
+	if c.LivenessProbe != nil {
+		if c.LivenessProbe.Path != nil {
+			livenessProbe.ProbeHandler.HTTPGet.Path = *c.LivenessProbe.Path
+		}
+		if c.LivenessProbe.InitialDelaySeconds != nil {
+			livenessProbe.InitialDelaySeconds = *c.LivenessProbe.InitialDelaySeconds
+		}
+		if c.LivenessProbe.PeriodSeconds != nil {
+			livenessProbe.PeriodSeconds = *c.LivenessProbe.PeriodSeconds
+		}
+		if c.LivenessProbe.TimeoutSeconds != nil {
+			livenessProbe.TimeoutSeconds = *c.LivenessProbe.TimeoutSeconds
+		}
+		if c.LivenessProbe.FailureThreshold != nil {
+			livenessProbe.FailureThreshold = *c.LivenessProbe.FailureThreshold
+		}
+	}
+	statefulSet.Spec.Template.Spec.Containers[0].LivenessProbe = livenessProbe
+
+	// the liveness probe is held off until the startup probe succeeds, so the filesystem scan
+	// of a large cache volume cannot trigger a restart mid-startup
+	startupProbe := &v1.Probe{
+		ProbeHandler:     *livenessProbe.ProbeHandler.DeepCopy(),
+		PeriodSeconds:    10,
+		TimeoutSeconds:   2,
+		FailureThreshold: 30,
+	}
+	if c.StartupProbe != nil {
+		if c.StartupProbe.PeriodSeconds != nil {
+			startupProbe.PeriodSeconds = *c.StartupProbe.PeriodSeconds
+		}
+		if c.StartupProbe.FailureThreshold != nil {
+			startupProbe.FailureThreshold = *c.StartupProbe.FailureThreshold
+		}
+	}
+	statefulSet.Spec.Template.Spec.Containers[0].StartupProbe = startupProbe
+
+	// by default readiness mirrors the liveness probe, so the pod keeps serving cached content
+	// while the upstream is down; with UpstreamReadiness the pod reports NotReady instead and
+	// containerd falls through to pulling directly from the upstream
+	if c.UpstreamReadiness {
+		// mirrors probeUpstream in the admission component: any HTTP response proves that a
+		// registry is served there, notably the 401 of an upstream requiring authentication.
+		// busybox wget exits non-zero on such responses without exposing the status code, so
+		// the script tells the "server returned error" case apart from connection failures
+		script := fmt.Sprintf(`out=$(wget -q -O /dev/null -T 5 %s/v2/ 2>&1) && exit 0
+case "$out" in *"server returned error"*) exit 0;; esac
+echo "$out" >&2
+exit 1
+`, UpstreamRegistryURL(c.Upstream, c.Insecure))
+		statefulSet.Spec.Template.Spec.Containers[0].ReadinessProbe = &v1.Probe{
+			ProbeHandler: v1.ProbeHandler{
+				Exec: &v1.ExecAction{
+					Command: []string{"sh", "-c", script},
+				},
+			},
+			InitialDelaySeconds: 5,
+			PeriodSeconds:       30,
+			TimeoutSeconds:      10,
+			FailureThreshold:    2,
+		}
+	} else {
+		readinessProbe := livenessProbe.DeepCopy()
+		readinessProbe.InitialDelaySeconds = 5
+		statefulSet.Spec.Template.Spec.Containers[0].ReadinessProbe = readinessProbe
+	}
+
+	{
+		// the storagedriver health check periodically writes to the cache volume, so a
+		// filesystem remounted read-only after I/O errors turns the registry unhealthy instead
+		// of failing writes and garbage collection silently; once the check fails, the health
+		// middleware answers all API requests with 503, which the RegistryCacheVolumeReadOnly
+		// alert picks up
+		registryContainer := &statefulSet.Spec.Template.Spec.Containers[0]
+		registryContainer.Env = append(registryContainer.Env,
+			v1.EnvVar{
+				Name:  environmentVariableNameRegistryHealthStorageEnabled,
+				Value: "true",
+			},
+			v1.EnvVar{
+				Name:  environmentVariableNameRegistryHealthStorageInterval,
+				Value: "30s",
+			},
+			v1.EnvVar{
+				Name:  environmentVariableNameRegistryHealthStorageThreshold,
+				Value: "3",
+			},
+		)
+	}
+	if c.Storage != nil {
+		registryContainer := &statefulSet.Spec.Template.Spec.Containers[0]
+		// render the parameters in a stable order so that repeated reconciliations do not
+		// produce spurious pod template changes
+		keys := make([]string, 0, len(c.Storage.DriverParameters))
+		for key := range c.Storage.DriverParameters {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			registryContainer.Env = append(registryContainer.Env, v1.EnvVar{
+				Name:  environmentVariableNameRegistryDriverPrefix + strings.ToUpper(key),
+				Value: c.Storage.DriverParameters[key],
+			})
+		}
+	}
+
+	if c.Schema1Compatibility != nil {
+		registryContainer := &statefulSet.Spec.Template.Spec.Containers[0]
+		registryContainer.Env = append(registryContainer.Env, v1.EnvVar{
+			Name:  environmentVariableNameRegistrySchema1Enabled,
+			Value: strconv.FormatBool(*c.Schema1Compatibility),
+		})
+	}
+
+	if c.HTTP2Enabled != nil && !*c.HTTP2Enabled {
+		registryContainer := &statefulSet.Spec.Template.Spec.Containers[0]
+		registryContainer.Env = append(registryContainer.Env, v1.EnvVar{
+			Name:  environmentVariableNameRegistryHTTP2Disabled,
+			Value: "true",
+		})
+	}
+	if c.HTTPHost != "" {
+		registryContainer := &statefulSet.Spec.Template.Spec.Containers[0]
+		registryContainer.Env = append(registryContainer.Env, v1.EnvVar{
+			Name:  environmentVariableNameRegistryHTTPHost,
+			Value: c.HTTPHost,
+		})
+	}
+
+	// the debug endpoint serves the prometheus metrics and, if enabled, the pprof handlers
+	debugPort := defaultRegistryCacheDebugPort
+	if c.Debug != nil && c.Debug.Port != nil {
+		debugPort = *c.Debug.Port
+	}
+	debugContainer := &statefulSet.Spec.Template.Spec.Containers[0]
+	debugContainer.Ports = append(debugContainer.Ports, v1.ContainerPort{
+		ContainerPort: debugPort,
+		Name:          registryCacheDebugPortName,
+	})
+	debugContainer.Env = append(debugContainer.Env,
+		v1.EnvVar{
+			Name:  environmentVariableNameRegistryDebugAddr,
+			Value: fmt.Sprintf(":%d", debugPort),
+		},
+		v1.EnvVar{
+			Name:  environmentVariableNameRegistryDebugPrometheusEnabled,
+			Value: "true",
+		},
+	)
+	if c.Debug != nil && c.Debug.PprofEnabled != nil && *c.Debug.PprofEnabled {
+		debugContainer.Env = append(debugContainer.Env, v1.EnvVar{
+			Name:  environmentVariableNameRegistryDebugPprofEnabled,
+			Value: "true",
+		})
+	}
+
+	if c.FSGroup != nil {
+		statefulSet.Spec.Template.Spec.SecurityContext.FSGroup = c.FSGroup
+	}
+	if c.FSGroupChangePolicy != nil {
+		fsGroupChangePolicy := v1.PodFSGroupChangePolicy(*c.FSGroupChangePolicy)
+		statefulSet.Spec.Template.Spec.SecurityContext.FSGroupChangePolicy = &fsGroupChangePolicy
+	}
+
+	if c.ImagePullPolicy != nil {
+		statefulSet.Spec.Template.Spec.Containers[0].ImagePullPolicy = v1.PullPolicy(*c.ImagePullPolicy)
+	}
+
+	if c.DNSPolicy != nil {
+		statefulSet.Spec.Template.Spec.DNSPolicy = v1.DNSPolicy(*c.DNSPolicy)
+	}
+	if c.DNSConfig != nil {
+		statefulSet.Spec.Template.Spec.DNSConfig = c.DNSConfig
+	}
+
+	if c.UpdateStrategy != nil {
+		updateStrategy := appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType}
+		if c.UpdateStrategy.Type != nil {
+			updateStrategy.Type = appsv1.StatefulSetUpdateStrategyType(*c.UpdateStrategy.Type)
+		}
+		if updateStrategy.Type == appsv1.RollingUpdateStatefulSetStrategyType && c.UpdateStrategy.Partition != nil {
+			updateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{Partition: c.UpdateStrategy.Partition}
+		}
+		statefulSet.Spec.UpdateStrategy = updateStrategy
+	}
+
+	if c.CATrustStore != nil {
+		c.ensureCATrustStore(statefulSet)
+	}
+
+	if c.PreferredAffinity != nil || c.NodeAffinity != nil {
+		affinity := &v1.Affinity{}
+		if c.PreferredAffinity != nil {
+			affinity.PodAffinity = &v1.PodAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []v1.WeightedPodAffinityTerm{{
+					Weight: pointer.Int32Deref(c.PreferredAffinity.Weight, 100),
+					PodAffinityTerm: v1.PodAffinityTerm{
+						LabelSelector: c.PreferredAffinity.LabelSelector.DeepCopy(),
+						TopologyKey:   pointer.StringDeref(c.PreferredAffinity.TopologyKey, "kubernetes.io/hostname"),
+						// the workloads run in arbitrary namespaces, not in the cache namespace
+						NamespaceSelector: &metav1.LabelSelector{},
+					},
+				}},
+			}
+		}
+		if c.NodeAffinity != nil {
+			affinity.NodeAffinity = c.NodeAffinity.DeepCopy()
+		}
+		statefulSet.Spec.Template.Spec.Affinity = affinity
+	}
+
+	// layers are content addressed and can never be recompressed in place, so the "ReportOnly"
+	// policy only observes the blob store and logs its size as an upper bound for the savings
+	// of a zstd recompression
+	if pointer.StringDeref(c.CompressionPolicy, v1alpha1.CompressionPolicyNone) == v1alpha1.CompressionPolicyReportOnly {
+		statefulSet.Spec.Template.Spec.Containers = append(statefulSet.Spec.Template.Spec.Containers, v1.Container{
+			Name:  compressionReportContainerName,
+			Image: c.RegistryImage.Repository,
+			Command: []string{"sh", "-c", `while true; do
+echo "blob store size (upper bound for zstd recompression savings):"
+du -s ` + registryVolumeMountPath + `/docker 2>/dev/null || true
+sleep 3600
+done
+`},
+			VolumeMounts: []v1.VolumeMount{{
+				Name:      registryCacheVolumeName,
+				ReadOnly:  true,
+				MountPath: registryVolumeMountPath,
+			}},
+		})
+	}
+
+	if len(c.UpstreamIPs) > 0 {
+		host := upstreamRegistryHost(c.Upstream)
+		for _, ip := range c.UpstreamIPs {
+			statefulSet.Spec.Template.Spec.HostAliases = append(statefulSet.Spec.Template.Spec.HostAliases, v1.HostAlias{
+				IP:        ip,
+				Hostnames: []string{host},
+			})
+		}
+	}
+
+	// the extra config mounts into the pod template, so it must be rendered before
+	// ensureDaemonSet derives its template from the StatefulSet
+	var extraConfigMap *v1.ConfigMap
+	if c.ExtraConfig != "" {
+		configMap, err := c.ensureExtraConfig(statefulSet)
+		if err != nil {
+			return nil, err
+		}
+		extraConfigMap = configMap
+	}
+
+	var workload client.Object = statefulSet
+	if c.Mode == v1alpha1.ModeDaemonSet {
+		workload = c.ensureDaemonSet(service, statefulSet)
+	}
+
+	objects := []client.Object{
 		service,
-		statefulSet,
-	}, nil
+		workload,
+	}
+
+	if c.Autoscaling != nil {
+		// the autoscaler owns the replica count; the annotation keeps the resource manager
+		// from resetting it to the rendered value with every reconciliation
+		if statefulSet.Annotations == nil {
+			statefulSet.Annotations = map[string]string{}
+		}
+		statefulSet.Annotations[resourcesv1alpha1.PreserveReplicas] = "true"
+		objects = append(objects, c.ensureAutoscaler())
+	}
+
+	if extraConfigMap != nil {
+		objects = append(objects, extraConfigMap)
+	}
+
+	if c.SmokeTest != nil {
+		objects = append(objects, c.ensureSmokeTestJob())
+	}
+
+	if c.EvictRepository != "" {
+		objects = append(objects, c.ensureEvictionJob())
+	}
+
+	// caches in the DaemonSet mode have no volume claim a garbage collection pod could
+	// mount; the annotation is already rejected for them, see resolveGCTarget
+	if c.ManualGC && c.Mode != v1alpha1.ModeDaemonSet {
+		objects = append(objects, c.ensureManualGCJob())
+	}
+
+	// caches in the DaemonSet mode have no volume claim a garbage collection pod could mount
+	if c.GarbageCollectionEnabled && c.Mode != v1alpha1.ModeDaemonSet {
+		objects = append(objects, c.ensureGCCronJob())
+	}
+
+	return objects, nil
+}
+
+// ensureSmokeTestJob builds the opt-in Job verifying end-to-end caching after a reconcile: it
+// pulls the manifest of the configured image through the cache service, asserts that the
+// repository afterwards shows up in the cache's catalog and pulls it a second time. The Job
+// only talks to the cache service; authentication against private upstreams is handled by the
+// cache itself, so no credentials are mounted. It gets its own labels so that it is not picked
+// up by the service selector and cleans up after itself via its TTL.
+func (c *registryCache) ensureSmokeTestJob() *batchv1.Job {
+	repository, tag := c.SmokeTest.Image, "latest"
+	if parts := strings.SplitN(repository, ":", 2); len(parts) == 2 {
+		repository, tag = parts[0], parts[1]
+	}
+
+	script := fmt.Sprintf(`set -e
+wget -q -O /dev/null http://%[1]s:5000/v2/%[2]s/manifests/%[3]s
+wget -q -O - http://%[1]s:5000/v2/_catalog | grep -q %[2]s
+wget -q -O /dev/null http://%[1]s:5000/v2/%[2]s/manifests/%[3]s
+`, c.Name, repository, tag)
+
+	labels := map[string]string{
+		"app": c.Name + "-smoke-test",
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.Name + "-smoke-test",
+			Namespace: c.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            pointer.Int32(2),
+			TTLSecondsAfterFinished: pointer.Int32(3600),
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: v1.PodSpec{
+					RestartPolicy:                v1.RestartPolicyNever,
+					AutomountServiceAccountToken: pointer.Bool(false),
+					Containers: []v1.Container{{
+						Name:    "smoke-test",
+						Image:   c.RegistryImage.Repository,
+						Command: []string{"sh", "-c", script},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// ensureEvictionJob builds the Job deleting all cached content of a single repository, e.g.
+// after a secret leaked into an image. Like the garbage collection runs the Job mounts the
+// cache volume, see gcPodSpec, and removes the repository directory of the storage backend;
+// the freed blobs are reclaimed by the next garbage collection run. The repository name is
+// validated by the actuator before it reaches the script, and the Job name carries a hash of
+// the repository, so a changed eviction target runs in a fresh Job instead of reusing a
+// completed one.
+func (c *registryCache) ensureEvictionJob() *batchv1.Job {
+	script := fmt.Sprintf(`set -e
+rm -rf %s/docker/registry/v2/repositories/%s
+`, registryVolumeMountPath, c.EvictRepository)
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(c.EvictRepository))
+	name := fmt.Sprintf("%s-evict-%08x", c.Name, hash.Sum32())
+
+	labels := map[string]string{
+		"app": c.Name + "-evict",
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: c.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            pointer.Int32(2),
+			TTLSecondsAfterFinished: pointer.Int32(3600),
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: v1.PodSpec{
+					RestartPolicy:                v1.RestartPolicyNever,
+					AutomountServiceAccountToken: pointer.Bool(false),
+					Affinity: &v1.Affinity{
+						PodAffinity: &v1.PodAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{{
+								LabelSelector: &metav1.LabelSelector{
+									MatchLabels: c.Labels,
+								},
+								TopologyKey: v1.LabelHostname,
+							}},
+						},
+					},
+					SecurityContext: &v1.PodSecurityContext{
+						RunAsNonRoot: pointer.Bool(true),
+						RunAsUser:    pointer.Int64(1000),
+					},
+					Containers: []v1.Container{{
+						Name:    "evict",
+						Image:   c.RegistryImage.Repository,
+						Command: []string{"sh", "-c", script},
+						VolumeMounts: []v1.VolumeMount{{
+							Name:      registryCacheVolumeName,
+							MountPath: registryVolumeMountPath,
+						}},
+					}},
+					Volumes: []v1.Volume{{
+						Name: registryCacheVolumeName,
+						VolumeSource: v1.VolumeSource{
+							PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+								ClaimName: cacheVolumeClaimName(c.Name),
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// ensureManualGCJob builds the Job starting a one-off garbage collection run for the cache,
+// requested via the garbage collection annotation on the Extension. Like the scheduled runs,
+// the Job invokes the garbage-collect CLI on the mounted cache volume, see gcPodSpec, so the
+// outcome of the Job reflects the run itself. The Job name is fixed per cache, so repeated
+// reconciliations with a pending annotation reuse one Job instead of starting parallel runs;
+// the actuator removes the annotation once the Job has finished, see clearFinishedManualGC.
+func (c *registryCache) ensureManualGCJob() *batchv1.Job {
+	labels := map[string]string{
+		"app": c.Name + "-gc",
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      manualGCJobName(c.Upstream),
+			Namespace: c.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            pointer.Int32(2),
+			TTLSecondsAfterFinished: pointer.Int32(3600),
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: c.gcPodSpec(),
+			},
+		},
+	}
+}
+
+// manualGCJobName returns the name of the one-off garbage collection Job of the cache for the
+// given upstream.
+func manualGCJobName(upstream string) string {
+	return cacheObjectName(upstream) + "-gc"
+}
+
+// ensureGCCronJob builds the CronJob running the scheduled garbage collection of the cache.
+// The registry only garbage-collects offline, via the "registry garbage-collect" CLI, so the
+// runs happen in a separate pod mounting the cache volume, see gcPodSpec. The start minute is
+// spread per upstream so that the runs of multiple caches do not line up.
+func (c *registryCache) ensureGCCronJob() *batchv1.CronJob {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(c.Upstream))
+
+	labels := map[string]string{
+		"app": c.Name + "-gc",
+	}
+
+	return &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.Name + "-gc",
+			Namespace: c.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:          fmt.Sprintf("%d 1 * * *", hash.Sum32()%60),
+			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: batchv1.JobSpec{
+					BackoffLimit:            pointer.Int32(2),
+					TTLSecondsAfterFinished: pointer.Int32(3600),
+					Template: v1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: labels,
+						},
+						Spec: c.gcPodSpec(),
+					},
+				},
+			},
+		},
+	}
+}
+
+// gcPodSpec returns the pod spec of a garbage collection run: the registry image invoking the
+// garbage-collect CLI against the image's default configuration file, whose root directory is
+// the mounted cache volume. The pod is placed onto the node of the cache pod via a required
+// pod affinity, so that it can mount the ReadWriteOnce volume of the StatefulSet.
+func (c *registryCache) gcPodSpec() v1.PodSpec {
+	command := []string{"registry", "garbage-collect", registryConfigMountPath + "/" + registryConfigKey}
+	if c.GarbageCollection != nil && pointer.BoolDeref(c.GarbageCollection.DeleteUntagged, false) {
+		command = append(command, "--delete-untagged")
+	}
+
+	return v1.PodSpec{
+		RestartPolicy:                v1.RestartPolicyNever,
+		AutomountServiceAccountToken: pointer.Bool(false),
+		Affinity: &v1.Affinity{
+			PodAffinity: &v1.PodAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{{
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: c.Labels,
+					},
+					TopologyKey: v1.LabelHostname,
+				}},
+			},
+		},
+		SecurityContext: &v1.PodSecurityContext{
+			RunAsNonRoot: pointer.Bool(true),
+			RunAsUser:    pointer.Int64(1000),
+		},
+		Containers: []v1.Container{{
+			Name:    "garbage-collect",
+			Image:   c.RegistryImage.Repository,
+			Command: command,
+			VolumeMounts: []v1.VolumeMount{{
+				Name:      registryCacheVolumeName,
+				MountPath: registryVolumeMountPath,
+			}},
+		}},
+		Volumes: []v1.Volume{{
+			Name: registryCacheVolumeName,
+			VolumeSource: v1.VolumeSource{
+				PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+					ClaimName: cacheVolumeClaimName(c.Name),
+				},
+			},
+		}},
+	}
+}
+
+// cacheVolumeClaimName returns the name of the PersistentVolumeClaim the volume claim template
+// of the cache StatefulSet creates for its single replica.
+func cacheVolumeClaimName(name string) string {
+	return fmt.Sprintf("%s-%s-0", registryCacheVolumeName, name)
+}
+
+// ensureDaemonSet derives the DaemonSet of a per-node cache from the pod template built for
+// the StatefulSet mode. The volume claim template is replaced by a hostPath volume on the node
+// and the registry port is published as host port, so that containerd on every node pulls
+// through localhost:<hostPort>.
+func (c *registryCache) ensureDaemonSet(service *v1.Service, statefulSet *appsv1.StatefulSet) *appsv1.DaemonSet {
+	service.Annotations[registryCacheServiceHostPortAnnotation] = strconv.FormatInt(int64(c.HostPort), 10)
+
+	hostPathType := v1.HostPathDirectoryOrCreate
+	template := statefulSet.Spec.Template
+	template.Spec.Volumes = append(template.Spec.Volumes, v1.Volume{
+		Name: registryCacheVolumeName,
+		VolumeSource: v1.VolumeSource{
+			HostPath: &v1.HostPathVolumeSource{
+				Path: registryCacheHostPathBase + "/" + c.Name,
+				Type: &hostPathType,
+			},
+		},
+	})
+	template.Spec.Containers[0].Ports[0].HostPort = c.HostPort
+
+	// the hostPath directory is created root-owned, but the registry runs as user 1000
+	template.Spec.InitContainers = append(template.Spec.InitContainers, v1.Container{
+		Name:            hostPathInitContainerName,
+		Image:           c.RegistryImage.Repository,
+		ImagePullPolicy: v1.PullIfNotPresent,
+		Command: []string{
+			"sh",
+			"-c",
+			"chown 1000:1000 " + registryVolumeMountPath,
+		},
+		SecurityContext: &v1.SecurityContext{
+			RunAsNonRoot: pointer.Bool(false),
+			RunAsUser:    pointer.Int64(0),
+		},
+		VolumeMounts: []v1.VolumeMount{{
+			Name:      registryCacheVolumeName,
+			MountPath: registryVolumeMountPath,
+		}},
+	})
+
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.Name,
+			Namespace: c.Namespace,
+			Labels:    c.Labels,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: c.Labels,
+			},
+			Template: template,
+		},
+	}
+}
+
+// requiredRegistryConfig returns the distribution config fields managed by the extension. They
+// take precedence over any extraConfig fragment so that a fragment cannot break the deployment.
+func requiredRegistryConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"version": "0.1",
+		"storage": map[string]interface{}{
+			"filesystem": map[string]interface{}{
+				"rootdirectory": registryVolumeMountPath,
+			},
+		},
+		"http": map[string]interface{}{
+			"addr": ":5000",
+		},
+	}
+}
+
+// mergeRegistryConfig deep-merges the extra config fragment with the required config. Values
+// from required take precedence; nested mappings are merged recursively.
+func mergeRegistryConfig(required, extra map[string]interface{}) map[string]interface{} {
+	merged := map[string]interface{}{}
+	for key, value := range extra {
+		merged[key] = value
+	}
+	for key, value := range required {
+		requiredMap, requiredOK := value.(map[string]interface{})
+		extraMap, extraOK := merged[key].(map[string]interface{})
+		if requiredOK && extraOK {
+			merged[key] = mergeRegistryConfig(requiredMap, extraMap)
+		} else {
+			merged[key] = value
+		}
+	}
+
+	return merged
+}
+
+// ensureExtraConfig renders the merged config.yml into a ConfigMap and mounts it at the path
+// the distribution image reads its configuration from.
+func (c *registryCache) ensureExtraConfig(statefulSet *appsv1.StatefulSet) (*v1.ConfigMap, error) {
+	extra := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(c.ExtraConfig), &extra); err != nil {
+		return nil, fmt.Errorf("failed to parse extraConfig for upstream %s: %w", c.Upstream, err)
+	}
+
+	configYAML, err := yaml.Marshal(mergeRegistryConfig(requiredRegistryConfig(), extra))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config.yml for upstream %s: %w", c.Upstream, err)
+	}
+
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.Name + "-config",
+			Namespace: c.Namespace,
+			Labels:    c.Labels,
+		},
+		Data: map[string]string{
+			registryConfigKey: string(configYAML),
+		},
+	}
+
+	podSpec := &statefulSet.Spec.Template.Spec
+	podSpec.Volumes = append(podSpec.Volumes, v1.Volume{
+		Name: registryConfigVolumeName,
+		VolumeSource: v1.VolumeSource{
+			ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{
+					Name: configMap.Name,
+				},
+			},
+		},
+	})
+	podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, v1.VolumeMount{
+		Name:      registryConfigVolumeName,
+		ReadOnly:  true,
+		MountPath: registryConfigMountPath,
+	})
+
+	return configMap, nil
+}
+
+// cacheObjectName returns the name of the shoot objects of the cache for the given upstream.
+// Ports are stripped and dots replaced, so that the name is a valid object name.
+func cacheObjectName(upstream string) string {
+	return strings.Replace(fmt.Sprintf("registry-%s", strings.Split(upstream, ":")[0]), ".", "-", -1)
+}
+
+// UpstreamRegistryURL returns the URL of the upstream registry itself. docker.io is a special
+// case because its registry is served at registry-1.docker.io. Insecure upstreams are addressed
+// via plain HTTP.
+func UpstreamRegistryURL(upstream string, insecure bool) string {
+	if upstream == "docker.io" {
+		return "https://registry-1.docker.io"
+	}
+
+	if insecure {
+		return "http://" + upstream
+	}
+
+	return "https://" + upstream
+}
+
+// profileResources maps a named resource profile to preset requests and limits of the registry
+// container. The profile names are validated, so an unknown profile cannot reach this point.
+func profileResources(profile string) v1.ResourceRequirements {
+	switch profile {
+	case v1alpha1.ResourceProfileSmall:
+		return v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("50m"),
+				v1.ResourceMemory: resource.MustParse("128Mi"),
+			},
+			Limits: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("200m"),
+				v1.ResourceMemory: resource.MustParse("512Mi"),
+			},
+		}
+	case v1alpha1.ResourceProfileMedium:
+		return v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("100m"),
+				v1.ResourceMemory: resource.MustParse("512Mi"),
+			},
+			Limits: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("500m"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		}
+	case v1alpha1.ResourceProfileLarge:
+		return v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("250m"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+			Limits: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("1"),
+				v1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+		}
+	}
+
+	return v1.ResourceRequirements{}
+}
+
+// ensureAutoscaler builds the HorizontalPodAutoscaler scaling the cache StatefulSet between
+// the configured replica bounds. The request-rate target consumes the per-pod request rate of
+// the caches, which a custom metrics adapter must serve from the scraped registry metrics.
+func (c *registryCache) ensureAutoscaler() *autoscalingv2.HorizontalPodAutoscaler {
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.Name,
+			Namespace: c.Namespace,
+			Labels:    c.Labels,
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "StatefulSet",
+				Name:       c.Name,
+			},
+			MinReplicas: pointer.Int32(pointer.Int32Deref(c.Autoscaling.MinReplicas, 1)),
+			MaxReplicas: c.Autoscaling.MaxReplicas,
+		},
+	}
+
+	if c.Autoscaling.TargetCPUUtilization != nil {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, autoscalingv2.MetricSpec{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name: v1.ResourceCPU,
+				Target: autoscalingv2.MetricTarget{
+					Type:               autoscalingv2.UtilizationMetricType,
+					AverageUtilization: c.Autoscaling.TargetCPUUtilization,
+				},
+			},
+		})
+	}
+	if c.Autoscaling.TargetMemoryUtilization != nil {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, autoscalingv2.MetricSpec{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name: v1.ResourceMemory,
+				Target: autoscalingv2.MetricTarget{
+					Type:               autoscalingv2.UtilizationMetricType,
+					AverageUtilization: c.Autoscaling.TargetMemoryUtilization,
+				},
+			},
+		})
+	}
+	return hpa
+}
+
+// upstreamRegistryHost returns the hostname of the upstream registry itself without a port,
+// i.e. the host part of upstreamRegistryURL.
+func upstreamRegistryHost(upstream string) string {
+	if upstream == "docker.io" {
+		return "registry-1.docker.io"
+	}
+
+	return strings.Split(upstream, ":")[0]
+}
+
+// serviceUpstream returns the upstream a cache service was created for. It prefers the
+// annotation and falls back to the label for services created before the annotation existed.
+func serviceUpstream(service *v1.Service) string {
+	if upstream, ok := service.Annotations[registryCacheServiceUpstreamAnnotation]; ok {
+		return upstream
+	}
+
+	return service.Labels[registryCacheServiceUpstreamLabel]
+}
+
+// blockCacheMissFetches reports whether cache-miss fetches from the upstream must be blocked
+// because the free space on the cache volume dropped below the configured minimum. Requests for
+// already-cached content are not affected by the threshold.
+func blockCacheMissFetches(freeBytes int64, minFreeBytes *resource.Quantity) bool {
+	return minFreeBytes != nil && freeBytes < minFreeBytes.Value()
+}
+
+// ensureCATrustStore mounts the referenced CA certificates into the system trust path of the
+// registry pod. An init container runs update-ca-certificates to render the combined trust
+// store into a volume shared with the registry container.
+func (c *registryCache) ensureCATrustStore(statefulSet *appsv1.StatefulSet) {
+	caTrustStoreVolume := v1.Volume{
+		Name: caTrustStoreVolumeName,
+	}
+	switch {
+	case c.CATrustStore.ConfigMapName != nil:
+		caTrustStoreVolume.VolumeSource = v1.VolumeSource{
+			ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{
+					Name: *c.CATrustStore.ConfigMapName,
+				},
+				Optional: pointer.Bool(false),
+			},
+		}
+	case c.CATrustStore.SecretName != nil:
+		caTrustStoreVolume.VolumeSource = v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName: *c.CATrustStore.SecretName,
+				Optional:   pointer.Bool(false),
+			},
+		}
+	}
+
+	podSpec := &statefulSet.Spec.Template.Spec
+	podSpec.Volumes = append(podSpec.Volumes,
+		caTrustStoreVolume,
+		v1.Volume{
+			Name: caCertificatesVolumeName,
+			VolumeSource: v1.VolumeSource{
+				EmptyDir: &v1.EmptyDirVolumeSource{},
+			},
+		},
+	)
+
+	podSpec.InitContainers = append(podSpec.InitContainers, v1.Container{
+		Name:            caTrustStoreInitContainerName,
+		Image:           c.RegistryImage.Repository,
+		ImagePullPolicy: v1.PullIfNotPresent,
+		Command: []string{
+			"sh",
+			"-c",
+			"update-ca-certificates",
+		},
+		VolumeMounts: []v1.VolumeMount{
+			{
+				Name:      caTrustStoreVolumeName,
+				ReadOnly:  true,
+				MountPath: caTrustStoreMountPath,
+			},
+			{
+				Name:      caCertificatesVolumeName,
+				MountPath: caCertificatesMountPath,
+			},
+		},
+	})
+
+	podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, v1.VolumeMount{
+		Name:      caCertificatesVolumeName,
+		ReadOnly:  true,
+		MountPath: caCertificatesMountPath,
+	})
 }
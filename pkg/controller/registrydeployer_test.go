@@ -0,0 +1,1344 @@
+package controller
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	resourcesv1alpha1 "github.com/gardener/gardener/pkg/apis/resources/v1alpha1"
+	"github.com/gardener/gardener/pkg/utils/imagevector"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha1"
+)
+
+// ensureStatefulSet calls Ensure and returns the StatefulSet from the ensured objects.
+func ensureStatefulSet(t *testing.T, c *registryCache) *appsv1.StatefulSet {
+	t.Helper()
+
+	objects, err := c.Ensure()
+	if err != nil {
+		t.Fatalf("registryCache.Ensure() error = %v", err)
+	}
+
+	for _, obj := range objects {
+		if statefulSet, ok := obj.(*appsv1.StatefulSet); ok {
+			return statefulSet
+		}
+	}
+
+	t.Fatal("registryCache.Ensure() did not return a StatefulSet")
+	return nil
+}
+
+// envValue returns the value of the named environment variable of the given container.
+func envValue(container corev1.Container, name string) string {
+	for _, env := range container.Env {
+		if env.Name == name {
+			return env.Value
+		}
+	}
+	return ""
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureCATrustStore(t *testing.T) {
+	c := &registryCache{
+		Upstream:   "docker.io",
+		VolumeSize: resource.MustParse("10Gi"),
+		CATrustStore: &v1alpha1.CATrustStore{
+			ConfigMapName: pointer.String("private-cas"),
+		},
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+
+	podSpec := statefulSet.Spec.Template.Spec
+	if len(podSpec.InitContainers) != 1 || podSpec.InitContainers[0].Name != caTrustStoreInitContainerName {
+		t.Errorf("expected init container %q, got %v", caTrustStoreInitContainerName, podSpec.InitContainers)
+	}
+
+	volumes := map[string]bool{}
+	for _, volume := range podSpec.Volumes {
+		volumes[volume.Name] = true
+		if volume.Name == caTrustStoreVolumeName && (volume.ConfigMap == nil || volume.ConfigMap.Name != "private-cas") {
+			t.Errorf("expected volume %q to reference ConfigMap private-cas, got %v", caTrustStoreVolumeName, volume.VolumeSource)
+		}
+	}
+	for _, name := range []string{caTrustStoreVolumeName, caCertificatesVolumeName} {
+		if !volumes[name] {
+			t.Errorf("expected volume %q to exist, got %v", name, podSpec.Volumes)
+		}
+	}
+
+	var mounted bool
+	for _, mount := range podSpec.Containers[0].VolumeMounts {
+		if mount.Name == caCertificatesVolumeName && mount.MountPath == caCertificatesMountPath && mount.ReadOnly {
+			mounted = true
+		}
+	}
+	if !mounted {
+		t.Errorf("expected registry container to mount %q at %q, got %v", caCertificatesVolumeName, caCertificatesMountPath, podSpec.Containers[0].VolumeMounts)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureUpdateStrategy(t *testing.T) {
+	c := &registryCache{
+		Upstream:   "docker.io",
+		VolumeSize: resource.MustParse("10Gi"),
+		UpdateStrategy: &v1alpha1.UpdateStrategy{
+			Type:      pointer.String(v1alpha1.UpdateStrategyTypeRollingUpdate),
+			Partition: pointer.Int32(1),
+		},
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+
+	updateStrategy := statefulSet.Spec.UpdateStrategy
+	if updateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		t.Errorf("expected update strategy type %q, got %q", appsv1.RollingUpdateStatefulSetStrategyType, updateStrategy.Type)
+	}
+	if updateStrategy.RollingUpdate == nil || updateStrategy.RollingUpdate.Partition == nil || *updateStrategy.RollingUpdate.Partition != 1 {
+		t.Errorf("expected rolling update partition 1, got %v", updateStrategy.RollingUpdate)
+	}
+
+	c.UpdateStrategy = &v1alpha1.UpdateStrategy{Type: pointer.String(v1alpha1.UpdateStrategyTypeOnDelete)}
+	statefulSet = ensureStatefulSet(t, c)
+	if statefulSet.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType {
+		t.Errorf("expected update strategy type %q, got %q", appsv1.OnDeleteStatefulSetStrategyType, statefulSet.Spec.UpdateStrategy.Type)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureServiceAccountName(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+
+	podSpec := statefulSet.Spec.Template.Spec
+	if podSpec.ServiceAccountName != registryCacheServiceAccountName {
+		t.Errorf("expected default service account %q, got %q", registryCacheServiceAccountName, podSpec.ServiceAccountName)
+	}
+	if podSpec.AutomountServiceAccountToken == nil || *podSpec.AutomountServiceAccountToken {
+		t.Errorf("expected the service account token mount to be disabled, got %v", podSpec.AutomountServiceAccountToken)
+	}
+
+	c.ServiceAccountName = "custom-sa"
+	if statefulSet = ensureStatefulSet(t, c); statefulSet.Spec.Template.Spec.ServiceAccountName != "custom-sa" {
+		t.Errorf("expected service account custom-sa, got %q", statefulSet.Spec.Template.Spec.ServiceAccountName)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureSecurityContext(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+
+	podSpec := statefulSet.Spec.Template.Spec
+	podSecurityContext := podSpec.SecurityContext
+	if podSecurityContext == nil || podSecurityContext.RunAsNonRoot == nil || !*podSecurityContext.RunAsNonRoot {
+		t.Errorf("expected the pod to run as non-root, got %v", podSecurityContext)
+	}
+	if podSecurityContext.SeccompProfile == nil || podSecurityContext.SeccompProfile.Type != corev1.SeccompProfileTypeRuntimeDefault {
+		t.Errorf("expected the RuntimeDefault seccomp profile, got %v", podSecurityContext.SeccompProfile)
+	}
+
+	containerSecurityContext := podSpec.Containers[0].SecurityContext
+	if containerSecurityContext == nil || containerSecurityContext.ReadOnlyRootFilesystem == nil || !*containerSecurityContext.ReadOnlyRootFilesystem {
+		t.Errorf("expected a read-only root filesystem, got %v", containerSecurityContext)
+	}
+	if containerSecurityContext.AllowPrivilegeEscalation == nil || *containerSecurityContext.AllowPrivilegeEscalation {
+		t.Errorf("expected privilege escalation to be disallowed, got %v", containerSecurityContext)
+	}
+	if containerSecurityContext.Capabilities == nil || len(containerSecurityContext.Capabilities.Drop) != 1 || containerSecurityContext.Capabilities.Drop[0] != "ALL" {
+		t.Errorf("expected all capabilities to be dropped, got %v", containerSecurityContext.Capabilities)
+	}
+
+	var tmpMounted bool
+	for _, mount := range podSpec.Containers[0].VolumeMounts {
+		if mount.Name == tmpVolumeName && mount.MountPath == tmpVolumeMountPath {
+			tmpMounted = true
+		}
+	}
+	if !tmpMounted {
+		t.Errorf("expected a writable tmp mount, got %v", podSpec.Containers[0].VolumeMounts)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureDNS(t *testing.T) {
+	c := &registryCache{
+		Upstream:   "docker.io",
+		VolumeSize: resource.MustParse("10Gi"),
+		DNSPolicy:  pointer.String(string(corev1.DNSNone)),
+		DNSConfig: &corev1.PodDNSConfig{
+			Nameservers: []string{"10.0.0.10"},
+			Searches:    []string{"cluster.local"},
+		},
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+
+	podSpec := statefulSet.Spec.Template.Spec
+	if podSpec.DNSPolicy != corev1.DNSNone {
+		t.Errorf("expected DNS policy %q, got %q", corev1.DNSNone, podSpec.DNSPolicy)
+	}
+	if podSpec.DNSConfig == nil || len(podSpec.DNSConfig.Nameservers) != 1 || podSpec.DNSConfig.Nameservers[0] != "10.0.0.10" {
+		t.Errorf("expected the DNS config to be propagated, got %v", podSpec.DNSConfig)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureFSGroup(t *testing.T) {
+	c := &registryCache{
+		Upstream:            "docker.io",
+		VolumeSize:          resource.MustParse("10Gi"),
+		FSGroup:             pointer.Int64(2000),
+		FSGroupChangePolicy: pointer.String(string(corev1.FSGroupChangeOnRootMismatch)),
+		RegistryImage:       &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+
+	securityContext := statefulSet.Spec.Template.Spec.SecurityContext
+	if securityContext == nil || securityContext.FSGroup == nil || *securityContext.FSGroup != 2000 {
+		t.Errorf("expected fsGroup 2000, got %v", securityContext)
+	}
+	if securityContext.FSGroupChangePolicy == nil || *securityContext.FSGroupChangePolicy != corev1.FSGroupChangeOnRootMismatch {
+		t.Errorf("expected fsGroupChangePolicy %q, got %v", corev1.FSGroupChangeOnRootMismatch, securityContext.FSGroupChangePolicy)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsurePathPrefix(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "proxy.example.com",
+		PathPrefix:    "/dockerhub",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+
+	registryContainer := statefulSet.Spec.Template.Spec.Containers[0]
+	if got := envValue(registryContainer, environmentVariableNameRegistryURL); got != "https://proxy.example.com/dockerhub" {
+		t.Errorf("expected %s=https://proxy.example.com/dockerhub, got %q", environmentVariableNameRegistryURL, got)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureHTTPSecretAndHost(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		HTTPHost:      "https://registry.example.com:5000",
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+
+	registryContainer := statefulSet.Spec.Template.Spec.Containers[0]
+	var secretRef *corev1.SecretKeySelector
+	for _, env := range registryContainer.Env {
+		if env.Name == environmentVariableNameRegistryHTTPSecret && env.ValueFrom != nil {
+			secretRef = env.ValueFrom.SecretKeyRef
+		}
+	}
+	if secretRef == nil || secretRef.Name != registryCacheHTTPSecretName || secretRef.Key != registryCacheHTTPSecretKey {
+		t.Errorf("expected %s to reference key %q of Secret %q, got %v", environmentVariableNameRegistryHTTPSecret, registryCacheHTTPSecretKey, registryCacheHTTPSecretName, secretRef)
+	}
+
+	if got := envValue(registryContainer, environmentVariableNameRegistryHTTPHost); got != "https://registry.example.com:5000" {
+		t.Errorf("expected %s=https://registry.example.com:5000, got %q", environmentVariableNameRegistryHTTPHost, got)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureLivenessProbe(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+
+	probe := statefulSet.Spec.Template.Spec.Containers[0].LivenessProbe
+	if probe == nil || probe.HTTPGet == nil || probe.HTTPGet.Path != "/v2/" {
+		t.Fatalf("expected a default liveness probe on /v2/, got %v", probe)
+	}
+	if probe.FailureThreshold != 6 {
+		t.Errorf("expected failure threshold 6, got %d", probe.FailureThreshold)
+	}
+
+	c.LivenessProbe = &v1alpha1.LivenessProbe{
+		Path:             pointer.String("/debug/health"),
+		PeriodSeconds:    pointer.Int32(30),
+		FailureThreshold: pointer.Int32(3),
+	}
+	statefulSet = ensureStatefulSet(t, c)
+
+	probe = statefulSet.Spec.Template.Spec.Containers[0].LivenessProbe
+	if probe.HTTPGet.Path != "/debug/health" || probe.PeriodSeconds != 30 || probe.FailureThreshold != 3 {
+		t.Errorf("expected the configured liveness probe values, got %v", probe)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureInsecureUpstream(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "registry.internal:5000",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+	if got := envValue(statefulSet.Spec.Template.Spec.Containers[0], environmentVariableNameRegistryURL); got != "https://registry.internal:5000" {
+		t.Errorf("expected an https remote URL by default, got %q", got)
+	}
+
+	c.Insecure = true
+	statefulSet = ensureStatefulSet(t, c)
+	if got := envValue(statefulSet.Spec.Template.Spec.Containers[0], environmentVariableNameRegistryURL); got != "http://registry.internal:5000" {
+		t.Errorf("expected an http remote URL for an insecure upstream, got %q", got)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureGarbageCollectionOptions(t *testing.T) {
+	c := &registryCache{
+		Upstream:                 "docker.io",
+		VolumeSize:               resource.MustParse("10Gi"),
+		GarbageCollectionEnabled: true,
+		GarbageCollection: &v1alpha1.GarbageCollection{
+			DeleteUntagged: pointer.Bool(true),
+		},
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	cronJob := ensureGCCronJob(t, c)
+	gcContainer := cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0]
+	if want := []string{"registry", "garbage-collect", "/etc/docker/registry/config.yml", "--delete-untagged"}; !reflect.DeepEqual(gcContainer.Command, want) {
+		t.Errorf("expected the GC command %v, got %v", want, gcContainer.Command)
+	}
+	if got := cronJob.Spec.JobTemplate.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName; got != "cache-volume-registry-docker-io-0" {
+		t.Errorf("expected the GC pod to mount the cache volume claim, got %q", got)
+	}
+	if cronJob.Spec.JobTemplate.Spec.Template.Spec.Affinity.PodAffinity == nil {
+		t.Error("expected a pod affinity co-locating the GC pod with the cache pod")
+	}
+
+	c.GarbageCollection.DeleteUntagged = pointer.Bool(false)
+	cronJob = ensureGCCronJob(t, c)
+	gcContainer = cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0]
+	if want := []string{"registry", "garbage-collect", "/etc/docker/registry/config.yml"}; !reflect.DeepEqual(gcContainer.Command, want) {
+		t.Errorf("expected the GC command without --delete-untagged, got %v", gcContainer.Command)
+	}
+	c.GarbageCollection.DeleteUntagged = pointer.Bool(true)
+
+	c.GarbageCollectionEnabled = false
+	objects, err := c.Ensure()
+	if err != nil {
+		t.Fatalf("Ensure() failed: %v", err)
+	}
+	for _, object := range objects {
+		if _, ok := object.(*batchv1.CronJob); ok {
+			t.Error("expected no GC CronJob with garbage collection disabled")
+		}
+	}
+}
+
+// ensureGCCronJob runs Ensure on the given cache and returns the generated GC CronJob.
+func ensureGCCronJob(t *testing.T, c *registryCache) *batchv1.CronJob {
+	t.Helper()
+
+	objects, err := c.Ensure()
+	if err != nil {
+		t.Fatalf("Ensure() failed: %v", err)
+	}
+	for _, object := range objects {
+		if cronJob, ok := object.(*batchv1.CronJob); ok {
+			return cronJob
+		}
+	}
+	t.Fatal("expected a GC CronJob to be generated")
+	return nil
+}
+
+// TODO: migrate to ginkgo test
+func Test_blockCacheMissFetches(t *testing.T) {
+	minFree := resource.MustParse("1Gi")
+
+	if blockCacheMissFetches(2<<30, &minFree) {
+		t.Error("expected fetches not to be blocked with enough free space")
+	}
+	if blockCacheMissFetches(1<<30, &minFree) {
+		t.Error("expected fetches not to be blocked exactly at the threshold")
+	}
+	if !blockCacheMissFetches(1<<30-1, &minFree) {
+		t.Error("expected fetches to be blocked below the threshold")
+	}
+	if blockCacheMissFetches(0, nil) {
+		t.Error("expected fetches not to be blocked without a configured threshold")
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureMinFreeBytes(t *testing.T) {
+	minFree := resource.MustParse("1Gi")
+	c := &registryCache{
+		Upstream:   "docker.io",
+		VolumeSize: resource.MustParse("10Gi"),
+		GarbageCollection: &v1alpha1.GarbageCollection{
+			MinFreeBytes: &minFree,
+		},
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+	if got := envValue(statefulSet.Spec.Template.Spec.Containers[0], environmentVariableNameRegistryGCReadOnly); got != "" {
+		t.Errorf("expected no read-only mode while the volume has enough free space, got %q", got)
+	}
+
+	c.VolumeBelowMinFree = true
+	statefulSet = ensureStatefulSet(t, c)
+	if got := envValue(statefulSet.Spec.Template.Spec.Containers[0], environmentVariableNameRegistryGCReadOnly); got != "true" {
+		t.Errorf("expected the read-only maintenance mode below the threshold, got %q", got)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_mergeRegistryConfig(t *testing.T) {
+	extra := map[string]interface{}{
+		"log": map[string]interface{}{
+			"level": "debug",
+		},
+		"storage": map[string]interface{}{
+			"filesystem": map[string]interface{}{
+				"rootdirectory": "/somewhere/else",
+			},
+			"cache": map[string]interface{}{
+				"blobdescriptor": "inmemory",
+			},
+		},
+	}
+
+	merged := mergeRegistryConfig(requiredRegistryConfig(), extra)
+
+	if got := merged["log"].(map[string]interface{})["level"]; got != "debug" {
+		t.Errorf("merged log.level = %v, want debug", got)
+	}
+	storage := merged["storage"].(map[string]interface{})
+	if got := storage["filesystem"].(map[string]interface{})["rootdirectory"]; got != registryVolumeMountPath {
+		t.Errorf("merged storage.filesystem.rootdirectory = %v, want the required value %s", got, registryVolumeMountPath)
+	}
+	if got := storage["cache"].(map[string]interface{})["blobdescriptor"]; got != "inmemory" {
+		t.Errorf("merged storage.cache.blobdescriptor = %v, want inmemory", got)
+	}
+	if got := merged["http"].(map[string]interface{})["addr"]; got != ":5000" {
+		t.Errorf("merged http.addr = %v, want :5000", got)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureExtraConfig(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		ExtraConfig:   "log:\n  level: debug\n",
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	objects, err := c.Ensure()
+	if err != nil {
+		t.Fatalf("registryCache.Ensure() error = %v", err)
+	}
+
+	var configMap *corev1.ConfigMap
+	for _, obj := range objects {
+		if cm, ok := obj.(*corev1.ConfigMap); ok {
+			configMap = cm
+		}
+	}
+	if configMap == nil {
+		t.Fatal("registryCache.Ensure() did not return a config ConfigMap")
+	}
+
+	configYAML := configMap.Data[registryConfigKey]
+	if !strings.Contains(configYAML, "level: debug") {
+		t.Errorf("config.yml does not contain the extra config fragment:\n%s", configYAML)
+	}
+	if !strings.Contains(configYAML, "rootdirectory: "+registryVolumeMountPath) {
+		t.Errorf("config.yml does not contain the required storage root directory:\n%s", configYAML)
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+	var mounted bool
+	for _, mount := range statefulSet.Spec.Template.Spec.Containers[0].VolumeMounts {
+		if mount.Name == registryConfigVolumeName && mount.MountPath == registryConfigMountPath {
+			mounted = true
+		}
+	}
+	if !mounted {
+		t.Errorf("config volume is not mounted at %s", registryConfigMountPath)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsurePreferredAffinity(t *testing.T) {
+	c := &registryCache{
+		Upstream:   "docker.io",
+		VolumeSize: resource.MustParse("10Gi"),
+		PreferredAffinity: &v1alpha1.PreferredAffinity{
+			LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "monolith"}},
+			Weight:        pointer.Int32(50),
+		},
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+
+	affinity := statefulSet.Spec.Template.Spec.Affinity
+	if affinity == nil || affinity.PodAffinity == nil || len(affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution) != 1 {
+		t.Fatal("expected exactly one preferred pod affinity term")
+	}
+
+	term := affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0]
+	if term.Weight != 50 {
+		t.Errorf("affinity term weight = %d, want 50", term.Weight)
+	}
+	if got := term.PodAffinityTerm.LabelSelector.MatchLabels["app"]; got != "monolith" {
+		t.Errorf("affinity term labelSelector app = %q, want monolith", got)
+	}
+	if term.PodAffinityTerm.TopologyKey != "kubernetes.io/hostname" {
+		t.Errorf("affinity term topologyKey = %q, want kubernetes.io/hostname", term.PodAffinityTerm.TopologyKey)
+	}
+	if term.PodAffinityTerm.NamespaceSelector == nil {
+		t.Error("affinity term does not select all namespaces")
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureHostAliases(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		UpstreamIPs:   []string{"52.1.1.1", "52.1.1.2"},
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+	hostAliases := statefulSet.Spec.Template.Spec.HostAliases
+	if len(hostAliases) != 2 {
+		t.Fatalf("expected 2 host aliases, got %d", len(hostAliases))
+	}
+	for i, ip := range c.UpstreamIPs {
+		if hostAliases[i].IP != ip {
+			t.Errorf("hostAliases[%d].IP = %q, want %q", i, hostAliases[i].IP, ip)
+		}
+		if len(hostAliases[i].Hostnames) != 1 || hostAliases[i].Hostnames[0] != "registry-1.docker.io" {
+			t.Errorf("hostAliases[%d].Hostnames = %v, want [registry-1.docker.io]", i, hostAliases[i].Hostnames)
+		}
+	}
+
+	c.UpstreamIPs = nil
+	statefulSet = ensureStatefulSet(t, c)
+	if len(statefulSet.Spec.Template.Spec.HostAliases) != 0 {
+		t.Errorf("expected no host aliases, got %v", statefulSet.Spec.Template.Spec.HostAliases)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_upstreamRegistryHost(t *testing.T) {
+	for upstream, want := range map[string]string{
+		"docker.io":        "registry-1.docker.io",
+		"ghcr.io":          "ghcr.io",
+		"my-registry:8443": "my-registry",
+	} {
+		if got := upstreamRegistryHost(upstream); got != want {
+			t.Errorf("upstreamRegistryHost(%q) = %q, want %q", upstream, got, want)
+		}
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureNodeAffinity(t *testing.T) {
+	nodeAffinity := &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key:      "worker.gardener.cloud/pool",
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{"cpu-worker"},
+				}},
+			}},
+		},
+	}
+
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		NodeAffinity:  nodeAffinity,
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+	affinity := statefulSet.Spec.Template.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil {
+		t.Fatal("expected a node affinity to be set")
+	}
+	if !reflect.DeepEqual(affinity.NodeAffinity, nodeAffinity) {
+		t.Errorf("node affinity = %+v, want %+v", affinity.NodeAffinity, nodeAffinity)
+	}
+
+	c.PreferredAffinity = &v1alpha1.PreferredAffinity{
+		LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "monolith"}},
+	}
+	statefulSet = ensureStatefulSet(t, c)
+	affinity = statefulSet.Spec.Template.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.PodAffinity == nil {
+		t.Fatal("expected both node affinity and pod affinity to be set")
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureDaemonSetMode(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		Mode:          v1alpha1.ModeDaemonSet,
+		HostPort:      5010,
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	objects, err := c.Ensure()
+	if err != nil {
+		t.Fatalf("registryCache.Ensure() error = %v", err)
+	}
+
+	var (
+		daemonSet *appsv1.DaemonSet
+		service   *corev1.Service
+	)
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *appsv1.DaemonSet:
+			daemonSet = o
+		case *appsv1.StatefulSet:
+			t.Error("expected no StatefulSet in DaemonSet mode")
+		case *corev1.Service:
+			service = o
+		}
+	}
+	if daemonSet == nil || service == nil {
+		t.Fatal("expected a DaemonSet and a Service to be ensured")
+	}
+
+	if got := service.Annotations[registryCacheServiceHostPortAnnotation]; got != "5010" {
+		t.Errorf("service host port annotation = %q, want 5010", got)
+	}
+	if got := daemonSet.Spec.Template.Spec.Containers[0].Ports[0].HostPort; got != 5010 {
+		t.Errorf("host port = %d, want 5010", got)
+	}
+
+	var hostPath string
+	for _, volume := range daemonSet.Spec.Template.Spec.Volumes {
+		if volume.Name == registryCacheVolumeName && volume.HostPath != nil {
+			hostPath = volume.HostPath.Path
+		}
+	}
+	if want := registryCacheHostPathBase + "/" + c.Name; hostPath != want {
+		t.Errorf("host path = %q, want %q", hostPath, want)
+	}
+
+	var initContainerFound bool
+	for _, container := range daemonSet.Spec.Template.Spec.InitContainers {
+		if container.Name == hostPathInitContainerName {
+			initContainerFound = true
+		}
+	}
+	if !initContainerFound {
+		t.Errorf("expected the %s init container", hostPathInitContainerName)
+	}
+
+	c.ExtraConfig = "log:\n  level: debug\n"
+	objects, err = c.Ensure()
+	if err != nil {
+		t.Fatalf("registryCache.Ensure() error = %v", err)
+	}
+	daemonSet = nil
+	for _, obj := range objects {
+		if o, ok := obj.(*appsv1.DaemonSet); ok {
+			daemonSet = o
+		}
+	}
+	var configVolumeFound bool
+	for _, volume := range daemonSet.Spec.Template.Spec.Volumes {
+		if volume.Name == registryConfigVolumeName {
+			configVolumeFound = true
+		}
+	}
+	if !configVolumeFound {
+		t.Error("expected the extra config volume in the DaemonSet pod template")
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureImagePullPolicy(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+	if got := statefulSet.Spec.Template.Spec.Containers[0].ImagePullPolicy; got != corev1.PullIfNotPresent {
+		t.Errorf("image pull policy = %q, want %q", got, corev1.PullIfNotPresent)
+	}
+
+	c.ImagePullPolicy = pointer.String(string(corev1.PullNever))
+	statefulSet = ensureStatefulSet(t, c)
+	if got := statefulSet.Spec.Template.Spec.Containers[0].ImagePullPolicy; got != corev1.PullNever {
+		t.Errorf("image pull policy = %q, want %q", got, corev1.PullNever)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureSmokeTestJob(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		SmokeTest:     &v1alpha1.SmokeTest{Image: "library/alpine:3.16"},
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	objects, err := c.Ensure()
+	if err != nil {
+		t.Fatalf("Ensure() failed: %v", err)
+	}
+
+	var job *batchv1.Job
+	for _, object := range objects {
+		if j, ok := object.(*batchv1.Job); ok {
+			job = j
+		}
+	}
+	if job == nil {
+		t.Fatal("expected a smoke-test job in the objects")
+	}
+
+	if job.Name != "registry-docker-io-smoke-test" {
+		t.Errorf("job name = %q, want registry-docker-io-smoke-test", job.Name)
+	}
+	if got := pointer.Int32Deref(job.Spec.TTLSecondsAfterFinished, 0); got != 3600 {
+		t.Errorf("ttlSecondsAfterFinished = %d, want 3600", got)
+	}
+	if job.Labels["app"] == c.Labels["app"] {
+		t.Error("the job must not share the labels of the cache pods")
+	}
+
+	script := job.Spec.Template.Spec.Containers[0].Command[2]
+	if !strings.Contains(script, "/v2/library/alpine/manifests/3.16") {
+		t.Errorf("script does not pull library/alpine:3.16 through the cache:\n%s", script)
+	}
+	if !strings.Contains(script, "/v2/_catalog") {
+		t.Errorf("script does not assert the cache hit via the catalog:\n%s", script)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureSchema1Compatibility(t *testing.T) {
+	c := &registryCache{
+		Upstream:             "docker.io",
+		VolumeSize:           resource.MustParse("10Gi"),
+		RegistryImage:        &imagevector.Image{Repository: "registry"},
+		Schema1Compatibility: pointer.Bool(true),
+	}
+	statefulSet := ensureStatefulSet(t, c)
+	if got := envValue(statefulSet.Spec.Template.Spec.Containers[0], environmentVariableNameRegistrySchema1Enabled); got != "true" {
+		t.Errorf("schema1 compatibility = %q, want true", got)
+	}
+
+	c = &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+	statefulSet = ensureStatefulSet(t, c)
+	if got := envValue(statefulSet.Spec.Template.Spec.Containers[0], environmentVariableNameRegistrySchema1Enabled); got != "" {
+		t.Errorf("schema1 compatibility = %q, want the registry default when unset", got)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureUploadPurging(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+		UploadPurging: &v1alpha1.UploadPurging{
+			Enabled:  pointer.Bool(true),
+			Age:      &metav1.Duration{Duration: 48 * time.Hour},
+			Interval: &metav1.Duration{Duration: 6 * time.Hour},
+			DryRun:   pointer.Bool(true),
+		},
+	}
+	statefulSet := ensureStatefulSet(t, c)
+	container := statefulSet.Spec.Template.Spec.Containers[0]
+
+	if got := envValue(container, environmentVariableNameRegistryUploadPurgingEnabled); got != "true" {
+		t.Errorf("upload purging enabled = %q, want true", got)
+	}
+	if got := envValue(container, environmentVariableNameRegistryUploadPurgingAge); got != "48h0m0s" {
+		t.Errorf("upload purging age = %q, want 48h0m0s", got)
+	}
+	if got := envValue(container, environmentVariableNameRegistryUploadPurgingInterval); got != "6h0m0s" {
+		t.Errorf("upload purging interval = %q, want 6h0m0s", got)
+	}
+	if got := envValue(container, environmentVariableNameRegistryUploadPurgingDryRun); got != "true" {
+		t.Errorf("upload purging dry run = %q, want true", got)
+	}
+
+	c = &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+	statefulSet = ensureStatefulSet(t, c)
+	container = statefulSet.Spec.Template.Spec.Containers[0]
+	if got := envValue(container, environmentVariableNameRegistryUploadPurgingEnabled); got != "" {
+		t.Errorf("upload purging enabled = %q, want the distribution default without tuning", got)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureAutoscaler(t *testing.T) {
+	c := &registryCache{
+		Upstream:   "docker.io",
+		VolumeSize: resource.MustParse("10Gi"),
+		Autoscaling: &v1alpha1.Autoscaling{
+			MaxReplicas:             4,
+			TargetCPUUtilization:    pointer.Int32(80),
+			TargetMemoryUtilization: pointer.Int32(70),
+		},
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	objects, err := c.Ensure()
+	if err != nil {
+		t.Fatalf("Ensure() failed: %v", err)
+	}
+
+	var (
+		hpa         *autoscalingv2.HorizontalPodAutoscaler
+		statefulSet *appsv1.StatefulSet
+	)
+	for _, object := range objects {
+		switch o := object.(type) {
+		case *autoscalingv2.HorizontalPodAutoscaler:
+			hpa = o
+		case *appsv1.StatefulSet:
+			statefulSet = o
+		}
+	}
+	if hpa == nil {
+		t.Fatal("expected a HorizontalPodAutoscaler to be generated")
+	}
+	if hpa.Spec.ScaleTargetRef.Kind != "StatefulSet" || hpa.Spec.ScaleTargetRef.Name != statefulSet.Name {
+		t.Errorf("expected the autoscaler to target the cache StatefulSet, got %+v", hpa.Spec.ScaleTargetRef)
+	}
+	if *hpa.Spec.MinReplicas != 1 || hpa.Spec.MaxReplicas != 4 {
+		t.Errorf("expected replica bounds [1, 4], got [%d, %d]", *hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas)
+	}
+	if len(hpa.Spec.Metrics) != 2 {
+		t.Fatalf("expected a CPU and a memory target, got %v", hpa.Spec.Metrics)
+	}
+	if hpa.Spec.Metrics[0].Resource == nil || *hpa.Spec.Metrics[0].Resource.Target.AverageUtilization != 80 {
+		t.Errorf("expected a CPU utilization target of 80, got %+v", hpa.Spec.Metrics[0])
+	}
+	if hpa.Spec.Metrics[1].Resource == nil || *hpa.Spec.Metrics[1].Resource.Target.AverageUtilization != 70 {
+		t.Errorf("expected a memory utilization target of 70, got %+v", hpa.Spec.Metrics[1])
+	}
+	if statefulSet.Annotations[resourcesv1alpha1.PreserveReplicas] != "true" {
+		t.Error("expected the StatefulSet replicas to be preserved for the autoscaler")
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureGroupLabel(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		Group:         "base-images",
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+	if got := statefulSet.Spec.Template.Labels[registryCacheGroupLabel]; got != "base-images" {
+		t.Errorf("expected the group label on the cache pods, got %q", got)
+	}
+	// the selectors must stay stable when the group changes: the StatefulSet selector is
+	// immutable and the Service must keep selecting the pods of the old group during a rollout
+	if _, ok := statefulSet.Spec.Selector.MatchLabels[registryCacheGroupLabel]; ok {
+		t.Error("expected the group label to be absent from the StatefulSet selector")
+	}
+
+	objects, err := c.Ensure()
+	if err != nil {
+		t.Fatalf("Ensure() failed: %v", err)
+	}
+	for _, object := range objects {
+		if service, ok := object.(*corev1.Service); ok {
+			if _, ok := service.Spec.Selector[registryCacheGroupLabel]; ok {
+				t.Error("expected the group label to be absent from the Service selector")
+			}
+		}
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureHTTP2Toggle(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+	container := statefulSet.Spec.Template.Spec.Containers[0]
+	if got := envValue(container, environmentVariableNameRegistryHTTP2Disabled); got != "" {
+		t.Errorf("expected HTTP/2 to stay enabled by default, got %q", got)
+	}
+
+	c.HTTP2Enabled = pointer.Bool(false)
+	statefulSet = ensureStatefulSet(t, c)
+	container = statefulSet.Spec.Template.Spec.Containers[0]
+	if got := envValue(container, environmentVariableNameRegistryHTTP2Disabled); got != "true" {
+		t.Errorf("expected HTTP/2 to be disabled, got %q", got)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureStorageHealthCheck(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+	container := statefulSet.Spec.Template.Spec.Containers[0]
+	if got := envValue(container, environmentVariableNameRegistryHealthStorageEnabled); got != "true" {
+		t.Errorf("expected the storagedriver health check to be enabled, got %q", got)
+	}
+	if got := envValue(container, environmentVariableNameRegistryHealthStorageInterval); got != "30s" {
+		t.Errorf("expected a 30s health check interval, got %q", got)
+	}
+	if got := envValue(container, environmentVariableNameRegistryHealthStorageThreshold); got != "3" {
+		t.Errorf("expected a threshold of 3 failed checks, got %q", got)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureManualGCJob(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		ManualGC:      true,
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	objects, err := c.Ensure()
+	if err != nil {
+		t.Fatalf("Ensure() failed: %v", err)
+	}
+
+	var job *batchv1.Job
+	for _, object := range objects {
+		if o, ok := object.(*batchv1.Job); ok {
+			job = o
+		}
+	}
+	if job == nil {
+		t.Fatal("expected a garbage collection job in the objects")
+	}
+
+	if job.Name != "registry-docker-io-gc" {
+		t.Errorf("job name = %q, want registry-docker-io-gc", job.Name)
+	}
+	wantCommand := []string{"registry", "garbage-collect", "/etc/docker/registry/config.yml"}
+	if !reflect.DeepEqual(job.Spec.Template.Spec.Containers[0].Command, wantCommand) {
+		t.Errorf("job command = %v, want %v", job.Spec.Template.Spec.Containers[0].Command, wantCommand)
+	}
+	claim := job.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim
+	if claim == nil || claim.ClaimName != "cache-volume-registry-docker-io-0" {
+		t.Errorf("job does not mount the cache volume claim: %+v", job.Spec.Template.Spec.Volumes[0])
+	}
+
+	c = &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+	objects, err = c.Ensure()
+	if err != nil {
+		t.Fatalf("Ensure() failed: %v", err)
+	}
+	for _, object := range objects {
+		if _, ok := object.(*batchv1.Job); ok {
+			t.Error("expected no job without a requested manual garbage collection")
+		}
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureEvictionJob(t *testing.T) {
+	c := &registryCache{
+		Upstream:        "docker.io",
+		VolumeSize:      resource.MustParse("10Gi"),
+		EvictRepository: "library/nginx",
+		RegistryImage:   &imagevector.Image{Repository: "registry"},
+	}
+
+	objects, err := c.Ensure()
+	if err != nil {
+		t.Fatalf("Ensure() failed: %v", err)
+	}
+
+	var job *batchv1.Job
+	for _, object := range objects {
+		if o, ok := object.(*batchv1.Job); ok {
+			job = o
+		}
+	}
+	if job == nil {
+		t.Fatal("expected an eviction job in the objects")
+	}
+
+	if !strings.HasPrefix(job.Name, "registry-docker-io-evict-") {
+		t.Errorf("job name = %q, want a registry-docker-io-evict- prefix", job.Name)
+	}
+	if job.Labels["app"] == c.Labels["app"] {
+		t.Error("the job must not share the labels of the cache pods")
+	}
+
+	command := job.Spec.Template.Spec.Containers[0].Command
+	if len(command) != 3 || command[0] != "sh" || command[1] != "-c" {
+		t.Fatalf("job command = %v, want a sh -c script", command)
+	}
+	// the registry image only ships busybox tooling, so the script must get by with rm on
+	// the mounted cache volume instead of registry API calls
+	if want := "rm -rf " + registryVolumeMountPath + "/docker/registry/v2/repositories/library/nginx"; !strings.Contains(command[2], want) {
+		t.Errorf("script does not remove the repository directory, want %q in:\n%s", want, command[2])
+	}
+	claim := job.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim
+	if claim == nil || claim.ClaimName != "cache-volume-registry-docker-io-0" {
+		t.Errorf("job does not mount the cache volume claim: %+v", job.Spec.Template.Spec.Volumes[0])
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureServiceSessionAffinity(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	objects, err := c.Ensure()
+	if err != nil {
+		t.Fatalf("Ensure() failed: %v", err)
+	}
+	service := objects[0].(*corev1.Service)
+	if service.Spec.SessionAffinity != "" {
+		t.Errorf("session affinity = %q, want none by default", service.Spec.SessionAffinity)
+	}
+
+	c.Service = &v1alpha1.Service{
+		SessionAffinity:               pointer.String(string(corev1.ServiceAffinityClientIP)),
+		SessionAffinityTimeoutSeconds: pointer.Int32(600),
+	}
+	objects, err = c.Ensure()
+	if err != nil {
+		t.Fatalf("Ensure() failed: %v", err)
+	}
+	service = objects[0].(*corev1.Service)
+	if service.Spec.SessionAffinity != corev1.ServiceAffinityClientIP {
+		t.Errorf("session affinity = %q, want %q", service.Spec.SessionAffinity, corev1.ServiceAffinityClientIP)
+	}
+	if service.Spec.SessionAffinityConfig == nil || service.Spec.SessionAffinityConfig.ClientIP == nil ||
+		pointer.Int32Deref(service.Spec.SessionAffinityConfig.ClientIP.TimeoutSeconds, 0) != 600 {
+		t.Errorf("session affinity config = %+v, want a ClientIP timeout of 600s", service.Spec.SessionAffinityConfig)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureServiceType(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	objects, err := c.Ensure()
+	if err != nil {
+		t.Fatalf("Ensure() failed: %v", err)
+	}
+	service := objects[0].(*corev1.Service)
+	if service.Spec.Type != corev1.ServiceTypeClusterIP {
+		t.Errorf("service type = %q, want %q by default", service.Spec.Type, corev1.ServiceTypeClusterIP)
+	}
+
+	c.Service = &v1alpha1.Service{Type: pointer.String(string(corev1.ServiceTypeNodePort))}
+	objects, err = c.Ensure()
+	if err != nil {
+		t.Fatalf("Ensure() failed: %v", err)
+	}
+	service = objects[0].(*corev1.Service)
+	if service.Spec.Type != corev1.ServiceTypeNodePort {
+		t.Errorf("service type = %q, want %q", service.Spec.Type, corev1.ServiceTypeNodePort)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureVolumeMode(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+	if statefulSet.Spec.VolumeClaimTemplates[0].Spec.VolumeMode != nil {
+		t.Errorf("volume mode = %q, want unset by default", *statefulSet.Spec.VolumeClaimTemplates[0].Spec.VolumeMode)
+	}
+
+	c.VolumeMode = pointer.String(string(corev1.PersistentVolumeFilesystem))
+	statefulSet = ensureStatefulSet(t, c)
+	if got := statefulSet.Spec.VolumeClaimTemplates[0].Spec.VolumeMode; got == nil || *got != corev1.PersistentVolumeFilesystem {
+		t.Errorf("volume mode = %v, want %q", got, corev1.PersistentVolumeFilesystem)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureDebugEndpoint(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+	container := statefulSet.Spec.Template.Spec.Containers[0]
+	if got := envValue(container, environmentVariableNameRegistryDebugAddr); got != ":5001" {
+		t.Errorf("%s = %q, want :5001", environmentVariableNameRegistryDebugAddr, got)
+	}
+	if got := envValue(container, environmentVariableNameRegistryDebugPrometheusEnabled); got != "true" {
+		t.Errorf("%s = %q, want true", environmentVariableNameRegistryDebugPrometheusEnabled, got)
+	}
+	if got := envValue(container, environmentVariableNameRegistryDebugPprofEnabled); got != "" {
+		t.Errorf("%s = %q, want pprof to be disabled by default", environmentVariableNameRegistryDebugPprofEnabled, got)
+	}
+	var debugPort *corev1.ContainerPort
+	for i := range container.Ports {
+		if container.Ports[i].Name == registryCacheDebugPortName {
+			debugPort = &container.Ports[i]
+		}
+	}
+	if debugPort == nil || debugPort.ContainerPort != 5001 {
+		t.Errorf("debug port = %+v, want a port named %q on 5001", debugPort, registryCacheDebugPortName)
+	}
+
+	c.Debug = &v1alpha1.Debug{
+		Port:         pointer.Int32(6060),
+		PprofEnabled: pointer.Bool(true),
+	}
+	statefulSet = ensureStatefulSet(t, c)
+	container = statefulSet.Spec.Template.Spec.Containers[0]
+	if got := envValue(container, environmentVariableNameRegistryDebugAddr); got != ":6060" {
+		t.Errorf("%s = %q, want :6060", environmentVariableNameRegistryDebugAddr, got)
+	}
+	if got := envValue(container, environmentVariableNameRegistryDebugPprofEnabled); got != "true" {
+		t.Errorf("%s = %q, want true", environmentVariableNameRegistryDebugPprofEnabled, got)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureCompressionReportSidecar(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+	if got := len(statefulSet.Spec.Template.Spec.Containers); got != 1 {
+		t.Errorf("container count = %d, want no sidecar by default", got)
+	}
+
+	c.CompressionPolicy = pointer.String(v1alpha1.CompressionPolicyReportOnly)
+	statefulSet = ensureStatefulSet(t, c)
+	if got := len(statefulSet.Spec.Template.Spec.Containers); got != 2 {
+		t.Fatalf("container count = %d, want the compression-report sidecar", got)
+	}
+	sidecar := statefulSet.Spec.Template.Spec.Containers[1]
+	if sidecar.Name != compressionReportContainerName {
+		t.Errorf("sidecar name = %q, want %q", sidecar.Name, compressionReportContainerName)
+	}
+	if len(sidecar.VolumeMounts) != 1 || !sidecar.VolumeMounts[0].ReadOnly {
+		t.Errorf("sidecar volume mounts = %+v, want a read-only mount of the cache volume", sidecar.VolumeMounts)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureStorageDriverParameters(t *testing.T) {
+	c := &registryCache{
+		Upstream:   "docker.io",
+		VolumeSize: resource.MustParse("10Gi"),
+		Storage: &v1alpha1.Storage{
+			DriverParameters: map[string]string{"maxthreads": "200"},
+		},
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+	container := statefulSet.Spec.Template.Spec.Containers[0]
+	if got := envValue(container, "REGISTRY_STORAGE_FILESYSTEM_MAXTHREADS"); got != "200" {
+		t.Errorf("REGISTRY_STORAGE_FILESYSTEM_MAXTHREADS = %q, want 200", got)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsurePodManagementPolicy(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+
+	if got := statefulSet.Spec.PodManagementPolicy; got != "" {
+		t.Errorf("expected the StatefulSet default pod management policy, got %q", got)
+	}
+
+	c.PodManagementPolicy = pointer.String(string(appsv1.ParallelPodManagement))
+	statefulSet = ensureStatefulSet(t, c)
+
+	if got := statefulSet.Spec.PodManagementPolicy; got != appsv1.ParallelPodManagement {
+		t.Errorf("expected the Parallel pod management policy, got %q", got)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureResourceProfile(t *testing.T) {
+	c := &registryCache{
+		Upstream:        "docker.io",
+		VolumeSize:      resource.MustParse("10Gi"),
+		RegistryImage:   &imagevector.Image{Repository: "registry"},
+		ResourceProfile: pointer.String(v1alpha1.ResourceProfileSmall),
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+
+	resources := statefulSet.Spec.Template.Spec.Containers[0].Resources
+	if got := resources.Requests.Memory().String(); got != "128Mi" {
+		t.Errorf("expected the memory request of the small profile, got %q", got)
+	}
+	if got := resources.Limits.Cpu().String(); got != "200m" {
+		t.Errorf("expected the cpu limit of the small profile, got %q", got)
+	}
+
+	c.ResourceProfile = nil
+	c.Resources = &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("3Gi")},
+	}
+	statefulSet = ensureStatefulSet(t, c)
+
+	resources = statefulSet.Spec.Template.Spec.Containers[0].Resources
+	if got := resources.Requests.Memory().String(); got != "3Gi" {
+		t.Errorf("expected the explicit memory request, got %q", got)
+	}
+	if len(resources.Limits) != 0 {
+		t.Errorf("expected no limits with explicit resources, got %v", resources.Limits)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureStartupProbe(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+
+	probe := statefulSet.Spec.Template.Spec.Containers[0].StartupProbe
+	if probe == nil || probe.HTTPGet == nil || probe.HTTPGet.Path != "/v2/" {
+		t.Fatalf("expected a default startup probe on /v2/, got %v", probe)
+	}
+	if probe.PeriodSeconds != 10 || probe.FailureThreshold != 30 {
+		t.Errorf("expected a generous default failure threshold, got %v", probe)
+	}
+
+	c.StartupProbe = &v1alpha1.StartupProbe{
+		PeriodSeconds:    pointer.Int32(15),
+		FailureThreshold: pointer.Int32(60),
+	}
+	statefulSet = ensureStatefulSet(t, c)
+
+	probe = statefulSet.Spec.Template.Spec.Containers[0].StartupProbe
+	if probe.PeriodSeconds != 15 || probe.FailureThreshold != 60 {
+		t.Errorf("expected the configured startup probe values, got %v", probe)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_registryCache_EnsureUpstreamReadiness(t *testing.T) {
+	c := &registryCache{
+		Upstream:      "docker.io",
+		VolumeSize:    resource.MustParse("10Gi"),
+		RegistryImage: &imagevector.Image{Repository: "registry"},
+	}
+
+	statefulSet := ensureStatefulSet(t, c)
+	readinessProbe := statefulSet.Spec.Template.Spec.Containers[0].ReadinessProbe
+	if readinessProbe == nil || readinessProbe.ProbeHandler.HTTPGet == nil || readinessProbe.ProbeHandler.HTTPGet.Path != "/v2/" {
+		t.Errorf("readiness probe = %+v, want the local /v2/ check by default", readinessProbe)
+	}
+
+	c.UpstreamReadiness = true
+	statefulSet = ensureStatefulSet(t, c)
+	readinessProbe = statefulSet.Spec.Template.Spec.Containers[0].ReadinessProbe
+	if readinessProbe == nil || readinessProbe.ProbeHandler.Exec == nil {
+		t.Fatalf("readiness probe = %+v, want an exec probe checking the upstream", readinessProbe)
+	}
+	command := strings.Join(readinessProbe.ProbeHandler.Exec.Command, " ")
+	if !strings.Contains(command, "https://registry-1.docker.io/v2/") {
+		t.Errorf("readiness probe command = %q, want it to check https://registry-1.docker.io/v2/", command)
+	}
+	if !strings.Contains(command, "server returned error") {
+		t.Errorf("readiness probe command = %q, want it to treat HTTP errors like 401 as reachable", command)
+	}
+}
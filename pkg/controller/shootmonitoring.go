@@ -0,0 +1,75 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// inShootPodMonitorName is the name of the PodMonitor generated for an in-shoot monitoring
+// stack, see inShootPodMonitorYAML.
+const inShootPodMonitorName = "registry-cache"
+
+// inShootPodMonitorYAML renders a prometheus-operator PodMonitor that lets a monitoring stack
+// running inside the shoot scrape the cache metrics in addition to the seed Prometheus. A
+// PodMonitor is generated rather than a ServiceMonitor because the metrics are served on the
+// debug container port, which the cache Service deliberately does not expose. The
+// prometheus-operator API is not part of the seed scheme, so the manifest is rendered as plain
+// YAML and added to the ManagedResource as-is; in shoots without the PodMonitor CRD the
+// resource-manager reports the ManagedResource as unhealthy until the CRD is installed.
+func inShootPodMonitorYAML(namespace string, extraLabels map[string]string) string {
+	var manifest strings.Builder
+	manifest.WriteString("apiVersion: monitoring.coreos.com/v1\n")
+	manifest.WriteString("kind: PodMonitor\n")
+	manifest.WriteString("metadata:\n")
+	manifest.WriteString("  name: " + inShootPodMonitorName + "\n")
+	manifest.WriteString("  namespace: " + namespace + "\n")
+	manifest.WriteString("  labels:\n")
+	manifest.WriteString("    app: " + inShootPodMonitorName + "\n")
+
+	keys := make([]string, 0, len(extraLabels))
+	for key := range extraLabels {
+		if key == "app" {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		manifest.WriteString(fmt.Sprintf("    %s: %q\n", key, extraLabels[key]))
+	}
+
+	manifest.WriteString(`spec:
+  selector:
+    matchExpressions:
+    - key: ` + registryCacheServiceUpstreamLabel + `
+      operator: Exists
+  podMetricsEndpoints:
+  - port: ` + registryCacheDebugPortName + `
+    relabelings:
+    - sourceLabels: [__meta_kubernetes_pod_label_upstream_host]
+      targetLabel: upstream_host
+    - sourceLabels: [__meta_kubernetes_pod_label_cache_group]
+      targetLabel: group
+    metricRelabelings:
+    - sourceLabels: [__name__]
+      action: keep
+      regex: registry_.+
+`)
+
+	return manifest.String()
+}
@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+)
+
+// TODO: migrate to ginkgo test
+func Test_inShootPodMonitorYAML(t *testing.T) {
+	manifest := inShootPodMonitorYAML("registry-cache", map[string]string{"release": "in-shoot-prometheus", "app": "overridden"})
+
+	for _, want := range []string{
+		"kind: PodMonitor",
+		"namespace: registry-cache",
+		"app: registry-cache",
+		`release: "in-shoot-prometheus"`,
+		"- key: " + registryCacheServiceUpstreamLabel,
+		"- port: " + registryCacheDebugPortName,
+		"regex: registry_.+",
+	} {
+		if !strings.Contains(manifest, want) {
+			t.Errorf("expected the PodMonitor manifest to contain %q, got:\n%s", want, manifest)
+		}
+	}
+	if strings.Contains(manifest, "overridden") {
+		t.Errorf("expected the app label not to be overridable, got:\n%s", manifest)
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gardener/gardener/pkg/utils/kubernetes"
+	"github.com/gardener/gardener/pkg/utils/timewindow"
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha1"
+)
+
+const (
+	// tierMigrationSuffix is appended to the cache object name to name the staging volume and
+	// the copy Job of a storage tier migration.
+	tierMigrationSuffix = "-tier-migration"
+	// tierMigrationVolumeName is the name of the staging volume in the copy Job and in the
+	// restore init container of the cache pod.
+	tierMigrationVolumeName = "tier-migration"
+	// tierMigrationMountPath is where the staging volume is mounted.
+	tierMigrationMountPath = "/tier-migration"
+	// tierRestoreInitContainerName restores the staged content into the fresh cache volume.
+	tierRestoreInitContainerName = "restore-tier"
+	// tierMigrationDeletionTimeout bounds the wait for the old cache pod and volume claim to
+	// be fully deleted before the cache is recreated on the target class.
+	tierMigrationDeletionTimeout = 2 * time.Minute
+	// tierRestoredMarker marks an already restored cache volume so that the restore init
+	// container copies at most once.
+	tierRestoredMarker = ".tier-restored"
+)
+
+// defaultHotBytesThreshold is the traffic above which a cache is placed on the hot tier when
+// the policy does not configure a threshold.
+var defaultHotBytesThreshold = resource.MustParse("10Gi")
+
+// tieredStorageClass selects the storage class of a cache from its observed traffic: caches
+// that served at least the configured amount of bytes belong on the hot tier, all others on
+// the cold tier. A storage class configured explicitly on the cache always wins. Nil is
+// returned when no tiering policy applies or when the traffic is unknown because the metrics
+// could not be read.
+func tieredStorageClass(cache v1alpha1.RegistryCache, tiering *v1alpha1.StorageTiering, pulledBytes map[string]int64) *string {
+	if cache.StorageClassName != nil {
+		return cache.StorageClassName
+	}
+	if tiering == nil || pulledBytes == nil {
+		return nil
+	}
+
+	threshold := defaultHotBytesThreshold
+	if tiering.HotBytesThreshold != nil {
+		threshold = *tiering.HotBytesThreshold
+	}
+
+	// the metric carries the upstream label, see registryCache.Ensure
+	if pulledBytes[cache.Upstream] >= threshold.Value() {
+		return pointer.String(tiering.HotStorageClassName)
+	}
+	return pointer.String(tiering.ColdStorageClassName)
+}
+
+// withinMigrationWindow reports whether volumes may migrate between tiers at the given time. A
+// policy without a migration window allows migrations at any time.
+func withinMigrationWindow(tiering *v1alpha1.StorageTiering, now time.Time) (bool, error) {
+	if tiering == nil || tiering.MigrationWindow == nil {
+		return true, nil
+	}
+
+	window, err := timewindow.ParseMaintenanceTimeWindow(tiering.MigrationWindow.Begin, tiering.MigrationWindow.End)
+	if err != nil {
+		return false, err
+	}
+
+	return window.Contains(now), nil
+}
+
+// reconcileStorageTier resolves the storage class of the cache and drives the migration of its
+// volume when the class changed. The migration is stateless and spans several reconciliations:
+// while the migration window is open, a staging volume on the target class is filled by a copy
+// Job next to the running cache, whose StatefulSet stays on its current class. Once the copy
+// succeeded, the StatefulSet and its old volume are deleted and, once both are fully gone,
+// the ManagedResource recreates them on the target class with an init container restoring the
+// staged content, see registryCache.Ensure. The returned objects must be added to the ManagedResource; once the
+// migrated cache is ready again, nothing is returned anymore and the staging objects are
+// pruned.
+func (a *actuator) reconcileStorageTier(ctx context.Context, log logr.Logger, shootClient client.Client, cache v1alpha1.RegistryCache, tiering *v1alpha1.StorageTiering, pulledBytes map[string]int64, c *registryCache) ([]client.Object, error) {
+	if cache.StorageClassName == nil && tiering == nil {
+		return nil, nil
+	}
+
+	var (
+		desired     = tieredStorageClass(cache, tiering, pulledBytes)
+		name        = cacheObjectName(cache.Upstream)
+		stagingName = name + tierMigrationSuffix
+		staging     = &corev1.PersistentVolumeClaim{}
+	)
+
+	deployed := &appsv1.StatefulSet{}
+	if err := shootClient.Get(ctx, client.ObjectKey{Name: name, Namespace: c.Namespace}, deployed); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		// the StatefulSet does not exist: either the cache is new and is created directly on
+		// the desired tier, or a migration just deleted it and it is recreated on the class of
+		// the staging volume with its content restored
+		if err := shootClient.Get(ctx, client.ObjectKey{Name: stagingName, Namespace: c.Namespace}, staging); err != nil {
+			if apierrors.IsNotFound(err) {
+				c.StorageClassName = desired
+				return nil, nil
+			}
+			return nil, err
+		}
+		c.StorageClassName = staging.Spec.StorageClassName
+		c.MigrateFromClaim = stagingName
+		return []client.Object{c.migrationClaim(staging.Spec.StorageClassName)}, nil
+	}
+
+	var deployedClass *string
+	if len(deployed.Spec.VolumeClaimTemplates) > 0 {
+		deployedClass = deployed.Spec.VolumeClaimTemplates[0].Spec.StorageClassName
+	}
+
+	if desired == nil || pointer.StringDeref(desired, "") == pointer.StringDeref(deployedClass, "") {
+		// unknown traffic keeps the deployed tier so that volumes do not flap between classes
+		c.StorageClassName = deployedClass
+
+		if err := shootClient.Get(ctx, client.ObjectKey{Name: stagingName, Namespace: c.Namespace}, staging); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if deployed.Status.ReadyReplicas > 0 {
+			// the migrated cache serves again; dropping the staging objects from the
+			// ManagedResource prunes them
+			return nil, nil
+		}
+		c.MigrateFromClaim = stagingName
+		return []client.Object{c.migrationClaim(staging.Spec.StorageClassName)}, nil
+	}
+
+	// the deployed volume is on the wrong tier
+	c.StorageClassName = deployedClass
+
+	within, err := withinMigrationWindow(tiering, a.now())
+	if err != nil {
+		return nil, err
+	}
+	if !within {
+		return nil, nil
+	}
+
+	job := &batchv1.Job{}
+	if err := shootClient.Get(ctx, client.ObjectKey{Name: stagingName, Namespace: c.Namespace}, job); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	} else if job.Status.Succeeded > 0 {
+		// the staged copy is complete; deleting the StatefulSet and its volume lets the
+		// ManagedResource recreate them on the target class
+		log.Info("completing storage tier migration", "upstream", cache.Upstream, "storageClass", *desired)
+		if err := shootClient.Delete(ctx, deployed); err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		oldClaim := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: registryCacheVolumeName + "-" + name + "-0", Namespace: c.Namespace}}
+		if err := shootClient.Delete(ctx, oldClaim); err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		// the claim carries a protection finalizer until the cache pod has terminated; a
+		// StatefulSet recreated before both are fully gone would bind the terminating claim
+		// and deadlock the migration, so the recreation is held back until the deletions
+		// went through
+		waitCtx, cancel := context.WithTimeout(ctx, tierMigrationDeletionTimeout)
+		defer cancel()
+		cachePod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name + "-0", Namespace: c.Namespace}}
+		for _, object := range []client.Object{cachePod, oldClaim} {
+			if err := kubernetes.WaitUntilResourceDeleted(waitCtx, shootClient, object, 2*time.Second); err != nil {
+				return nil, fmt.Errorf("old cache objects of the tier migration for upstream %s are not deleted yet: %w", cache.Upstream, err)
+			}
+		}
+		c.StorageClassName = desired
+		c.MigrateFromClaim = stagingName
+		return []client.Object{c.migrationClaim(desired)}, nil
+	}
+
+	log.Info("staging storage tier migration", "upstream", cache.Upstream, "storageClass", *desired)
+	return []client.Object{c.migrationClaim(desired), c.migrationJob()}, nil
+}
+
+// migrationClaim builds the staging volume of a storage tier migration on the given class. It
+// mirrors the size of the cache volume so that the full content fits.
+func (c *registryCache) migrationClaim(storageClassName *string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cacheObjectName(c.Upstream) + tierMigrationSuffix,
+			Namespace: c.Namespace,
+			Labels: map[string]string{
+				"app": cacheObjectName(c.Upstream) + tierMigrationSuffix,
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: storageClassName,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: c.VolumeSize,
+				},
+			},
+		},
+	}
+}
+
+// migrationJob builds the Job copying the content of the cache volume to the staging volume of
+// a storage tier migration. The cache keeps serving from its volume during the copy; content
+// cached after the copy is simply fetched from the upstream again once the migration is done.
+// The Job carries no TTL because its lifecycle is managed through the ManagedResource.
+func (c *registryCache) migrationJob() *batchv1.Job {
+	name := cacheObjectName(c.Upstream)
+	labels := map[string]string{
+		"app": name + tierMigrationSuffix,
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + tierMigrationSuffix,
+			Namespace: c.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: pointer.Int32(2),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:                corev1.RestartPolicyNever,
+					AutomountServiceAccountToken: pointer.Bool(false),
+					// the copy must run on the node of the cache pod so that both can mount
+					// the ReadWriteOnce cache volume
+					Affinity: &corev1.Affinity{
+						PodAffinity: &corev1.PodAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+								LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+								TopologyKey:   corev1.LabelHostname,
+							}},
+						},
+					},
+					Containers: []corev1.Container{{
+						Name:    "copy",
+						Image:   c.RegistryImage.Repository,
+						Command: []string{"sh", "-c", "set -e; cp -a /source/. " + tierMigrationMountPath + "/"},
+						VolumeMounts: []corev1.VolumeMount{
+							{
+								Name:      "source",
+								ReadOnly:  true,
+								MountPath: "/source",
+							},
+							{
+								Name:      tierMigrationVolumeName,
+								MountPath: tierMigrationMountPath,
+							},
+						},
+					}},
+					Volumes: []corev1.Volume{
+						{
+							Name: "source",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: registryCacheVolumeName + "-" + name + "-0",
+									ReadOnly:  true,
+								},
+							},
+						},
+						{
+							Name: tierMigrationVolumeName,
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: name + tierMigrationSuffix,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
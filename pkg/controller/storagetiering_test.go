@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"testing"
+
+	"k8s.io/utils/pointer"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha1"
+)
+
+// TODO: migrate to ginkgo test
+func Test_tieredStorageClass(t *testing.T) {
+	tiering := &v1alpha1.StorageTiering{
+		HotStorageClassName:  "ssd",
+		ColdStorageClassName: "hdd",
+	}
+
+	tests := []struct {
+		name        string
+		cache       v1alpha1.RegistryCache
+		tiering     *v1alpha1.StorageTiering
+		pulledBytes map[string]int64
+		want        *string
+	}{
+		{
+			name:        "explicit storage class wins over the tiering policy",
+			cache:       v1alpha1.RegistryCache{Upstream: "docker.io", StorageClassName: pointer.String("premium")},
+			tiering:     tiering,
+			pulledBytes: map[string]int64{"docker.io": 1 << 40},
+			want:        pointer.String("premium"),
+		},
+		{
+			name:  "no tiering policy",
+			cache: v1alpha1.RegistryCache{Upstream: "docker.io"},
+		},
+		{
+			name:        "hot cache above the default threshold",
+			cache:       v1alpha1.RegistryCache{Upstream: "docker.io"},
+			tiering:     tiering,
+			pulledBytes: map[string]int64{"docker.io": 11 << 30},
+			want:        pointer.String("ssd"),
+		},
+		{
+			name:        "cold cache below the default threshold",
+			cache:       v1alpha1.RegistryCache{Upstream: "docker.io"},
+			tiering:     tiering,
+			pulledBytes: map[string]int64{"docker.io": 1 << 30},
+			want:        pointer.String("hdd"),
+		},
+		{
+			name:  "custom threshold",
+			cache: v1alpha1.RegistryCache{Upstream: "docker.io"},
+			tiering: &v1alpha1.StorageTiering{
+				HotStorageClassName:  "ssd",
+				ColdStorageClassName: "hdd",
+				HotBytesThreshold:    resourceQuantityPtr("1Gi"),
+			},
+			pulledBytes: map[string]int64{"docker.io": 2 << 30},
+			want:        pointer.String("ssd"),
+		},
+		{
+			name:    "unknown traffic selects no tier",
+			cache:   v1alpha1.RegistryCache{Upstream: "docker.io"},
+			tiering: tiering,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tieredStorageClass(tt.cache, tt.tiering, tt.pulledBytes)
+			if pointer.StringDeref(got, "<nil>") != pointer.StringDeref(tt.want, "<nil>") {
+				t.Errorf("tieredStorageClass() = %v, want %v", pointer.StringDeref(got, "<nil>"), pointer.StringDeref(tt.want, "<nil>"))
+			}
+		})
+	}
+}
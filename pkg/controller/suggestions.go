@@ -0,0 +1,108 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha1"
+)
+
+// suggestedUpstreams returns the upstream registry hosts of the images running in the shoot
+// that have no configured cache yet, ordered by the number of containers using them (most-used
+// first, ties broken alphabetically). Node-local registries are never suggested.
+func suggestedUpstreams(registryConfig *v1alpha1.RegistryConfig, pods []corev1.Pod) []string {
+	usage := map[string]int{}
+	for _, pod := range pods {
+		for _, container := range pod.Spec.InitContainers {
+			usage[imageUpstream(container.Image)]++
+		}
+		for _, container := range pod.Spec.Containers {
+			usage[imageUpstream(container.Image)]++
+		}
+	}
+
+	var suggestions []string
+	for upstream := range usage {
+		if strings.HasPrefix(upstream, "localhost") || strings.HasPrefix(upstream, "127.") {
+			continue
+		}
+		if upstreamCached(registryConfig, upstream) {
+			continue
+		}
+		suggestions = append(suggestions, upstream)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if usage[suggestions[i]] != usage[suggestions[j]] {
+			return usage[suggestions[i]] > usage[suggestions[j]]
+		}
+		return suggestions[i] < suggestions[j]
+	})
+
+	return suggestions
+}
+
+// gardenerSystemRegistries are the registries known to host the images of Gardener system
+// components, see systemImageUpstreams.
+var gardenerSystemRegistries = map[string]struct{}{
+	"europe-docker.pkg.dev": {},
+	"eu.gcr.io":             {},
+	"ghcr.io":               {},
+	"quay.io":               {},
+	"registry.k8s.io":       {},
+}
+
+// systemImageUpstreams returns the upstream registry hosts of the given system component pods
+// that are known Gardener system registries and have no configured cache yet. Restricting the
+// discovery to the known registries keeps workload registries that happen to run pods in the
+// system namespace from silently getting a cache.
+func systemImageUpstreams(registryConfig *v1alpha1.RegistryConfig, pods []corev1.Pod) []string {
+	var upstreams []string
+	for _, upstream := range suggestedUpstreams(registryConfig, pods) {
+		if _, ok := gardenerSystemRegistries[upstream]; ok {
+			upstreams = append(upstreams, upstream)
+		}
+	}
+
+	return upstreams
+}
+
+// upstreamCached reports whether a cache is configured for the given upstream.
+func upstreamCached(registryConfig *v1alpha1.RegistryConfig, upstream string) bool {
+	for _, cache := range registryConfig.Caches {
+		if cache.Upstream == upstream {
+			return true
+		}
+	}
+
+	return false
+}
+
+// imageUpstream extracts the registry host of an image reference. Following the docker
+// convention, a reference whose first path segment is not a host belongs to docker.io.
+func imageUpstream(image string) string {
+	host, _, found := strings.Cut(image, "/")
+	if !found {
+		return "docker.io"
+	}
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		return "docker.io"
+	}
+
+	return host
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha1"
+)
+
+func podWithImages(images ...string) corev1.Pod {
+	pod := corev1.Pod{}
+	for _, image := range images {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Image: image})
+	}
+	return pod
+}
+
+// TODO: migrate to ginkgo test
+func Test_suggestedUpstreams(t *testing.T) {
+	registryConfig := &v1alpha1.RegistryConfig{
+		Caches: []v1alpha1.RegistryCache{
+			{Upstream: "docker.io"},
+			{Upstream: "registry.example.com"},
+		},
+	}
+
+	pods := []corev1.Pod{
+		podWithImages("alpine:3.16", "quay.io/prometheus/node-exporter:v1.3.1"),
+		podWithImages("eu.gcr.io/project/app:v1", "quay.io/coreos/etcd:v3.5"),
+		podWithImages("eu.gcr.io/project/sidecar:v2", "registry.example.com/team/tool:v1"),
+		podWithImages("localhost:5001/library/alpine:3.16"),
+	}
+	pods[0].Spec.InitContainers = []corev1.Container{{Image: "eu.gcr.io/project/init:v1"}}
+
+	got := suggestedUpstreams(registryConfig, pods)
+	want := []string{"eu.gcr.io", "quay.io"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("suggestedUpstreams() = %v, want %v", got, want)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_systemImageUpstreams(t *testing.T) {
+	registryConfig := &v1alpha1.RegistryConfig{
+		Caches: []v1alpha1.RegistryCache{
+			{Upstream: "registry.k8s.io"},
+		},
+	}
+
+	pods := []corev1.Pod{
+		podWithImages("registry.k8s.io/kube-proxy:v1.24.0", "eu.gcr.io/gardener-project/gardener/node-agent:v1.56.0"),
+		podWithImages("europe-docker.pkg.dev/gardener-project/releases/gardener/metrics-exporter:v0.1.0"),
+		podWithImages("quay.io/calico/node:v3.23", "registry.example.com/team/tool:v1"),
+		podWithImages("alpine:3.16"),
+	}
+
+	got := systemImageUpstreams(registryConfig, pods)
+	want := []string{"eu.gcr.io", "europe-docker.pkg.dev", "quay.io"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("systemImageUpstreams() = %v, want %v", got, want)
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_imageUpstream(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "alpine:3.16", want: "docker.io"},
+		{image: "library/alpine", want: "docker.io"},
+		{image: "quay.io/coreos/etcd:v3.5", want: "quay.io"},
+		{image: "localhost:5001/library/alpine", want: "localhost:5001"},
+		{image: "registry.example.com:5000/team/tool@sha256:abc", want: "registry.example.com:5000"},
+	}
+	for _, tt := range tests {
+		if got := imageUpstream(tt.image); got != tt.want {
+			t.Errorf("imageUpstream(%q) = %q, want %q", tt.image, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package snapshot allows exporting the contents of a registry cache volume as a
+// gzipped tar stream and importing such a stream into a fresh cache volume. It is
+// used to seed new caches from an existing one instead of warming them up again
+// against the upstream registry.
+package snapshot
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Create writes the contents of sourceDir as a gzipped tar stream to w. Paths inside
+// the archive are relative to sourceDir, so the archive can be restored into any
+// target directory.
+func Create(sourceDir string, w io.Writer) error {
+	sourceInfo, err := os.Stat(sourceDir)
+	if err != nil {
+		return fmt.Errorf("could not stat source directory: %w", err)
+	}
+	if !sourceInfo.IsDir() {
+		return fmt.Errorf("source %q is not a directory", sourceDir)
+	}
+
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	if err := filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(sourceDir, path)
+		if err != nil {
+			return err
+		}
+		if relativePath == "." {
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relativePath)
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(tarWriter, file)
+		return err
+	}); err != nil {
+		return fmt.Errorf("could not archive %q: %w", sourceDir, err)
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// Restore extracts a gzipped tar stream produced by Create into targetDir. Entries
+// that would escape targetDir are rejected.
+func Restore(r io.Reader, targetDir string) error {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("could not open snapshot stream: %w", err)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not read snapshot stream: %w", err)
+		}
+
+		path, err := sanitizePath(targetDir, header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode))
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(file, tarReader); err != nil {
+				file.Close()
+				return err
+			}
+			if err := file.Close(); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported entry type %d for %q", header.Typeflag, header.Name)
+		}
+	}
+}
+
+func sanitizePath(targetDir, name string) (string, error) {
+	path := filepath.Join(targetDir, filepath.FromSlash(name))
+	if !strings.HasPrefix(path, filepath.Clean(targetDir)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("entry %q escapes the target directory", name)
+	}
+	return path, nil
+}
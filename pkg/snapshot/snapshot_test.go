@@ -0,0 +1,84 @@
+// Copyright (c) 2022 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/snapshot"
+)
+
+// TODO: migrate to ginkgo test
+func Test_snapshot_RoundTrip(t *testing.T) {
+	sourceDir := t.TempDir()
+	files := map[string]string{
+		"docker/registry/v2/repositories/library/alpine/_manifests/link": "sha256:abc",
+		"docker/registry/v2/blobs/sha256/ab/abc/data":                    "blob-data",
+	}
+	for name, content := range files {
+		path := filepath.Join(sourceDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := snapshot.Create(sourceDir, &buffer); err != nil {
+		t.Fatalf("snapshot.Create() error = %v", err)
+	}
+
+	targetDir := t.TempDir()
+	if err := snapshot.Restore(bytes.NewReader(buffer.Bytes()), targetDir); err != nil {
+		t.Fatalf("snapshot.Restore() error = %v", err)
+	}
+
+	for name, want := range files {
+		got, err := os.ReadFile(filepath.Join(targetDir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("could not read restored file %q: %v", name, err)
+		}
+		if string(got) != want {
+			t.Errorf("restored file %q = %q, want %q", name, got, want)
+		}
+	}
+}
+
+// TODO: migrate to ginkgo test
+func Test_snapshot_RestoreRejectsPathTraversal(t *testing.T) {
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	if err := tarWriter.WriteHeader(&tar.Header{Name: "../escape", Typeflag: tar.TypeReg, Mode: 0644}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := snapshot.Restore(bytes.NewReader(buffer.Bytes()), t.TempDir()); err == nil {
+		t.Error("snapshot.Restore() expected an error for an entry escaping the target directory")
+	}
+}